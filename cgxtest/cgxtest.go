@@ -0,0 +1,182 @@
+// Package cgxtest records spans in memory to test the transactions the
+// CoralogixSampler assigns them:
+//
+//	recorder := cgxtest.NewRecorder(t)
+//	ctx, span := recorder.Tracer("test").Start(ctx, "POST /orders", trace.WithSpanKind(trace.SpanKindServer))
+//	...
+//	recorder.AssertTransaction(span, "POST /orders").AssertRoot(span)
+package cgxtest
+
+import (
+	"context"
+	"testing"
+
+	"go.opentelemetry.io/otel/attribute"
+	traceSdk "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	traceCore "go.opentelemetry.io/otel/trace"
+
+	"github.com/coralogix/coralogix-opentelemetry-go/sampler"
+)
+
+// Recorder records the spans of a tracer provider sampling with the
+// CoralogixSampler, and asserts their transactions. Its assertions report
+// failures to the test it was created with, and return the recorder so that
+// they can be chained.
+type Recorder struct {
+	t        testing.TB
+	recorder *tracetest.SpanRecorder
+	provider *traceSdk.TracerProvider
+}
+
+// NewRecorder returns a recorder whose tracer provider samples every span with
+// the CoralogixSampler. The options are applied after these defaults, and the
+// provider is shut down when the test ends.
+func NewRecorder(t testing.TB, options ...traceSdk.TracerProviderOption) *Recorder {
+	recorder := tracetest.NewSpanRecorder()
+	options = append([]traceSdk.TracerProviderOption{
+		traceSdk.WithSampler(sampler.NewCoralogixSampler(traceSdk.AlwaysSample())),
+		traceSdk.WithSpanProcessor(recorder),
+	}, options...)
+	provider := traceSdk.NewTracerProvider(options...)
+	t.Cleanup(func() {
+		_ = provider.Shutdown(context.Background())
+	})
+	return &Recorder{t: t, recorder: recorder, provider: provider}
+}
+
+func (r *Recorder) TracerProvider() *traceSdk.TracerProvider {
+	return r.provider
+}
+
+func (r *Recorder) Tracer(name string, options ...traceCore.TracerOption) traceCore.Tracer {
+	return r.provider.Tracer(name, options...)
+}
+
+// Ended returns the spans that ended, in the order they ended.
+func (r *Recorder) Ended() []traceSdk.ReadOnlySpan {
+	return r.recorder.Ended()
+}
+
+// Span returns the last ended span named name, failing the test if there is
+// none.
+func (r *Recorder) Span(name string) traceSdk.ReadOnlySpan {
+	r.t.Helper()
+	if span := r.lastEnded(name, nil); span != nil {
+		return span
+	}
+	r.t.Fatalf("no ended span named %q", name)
+	return nil
+}
+
+// SpanOfKind returns the last ended span named name of kind, failing the test
+// if there is none, to tell apart the client and server spans of a call.
+func (r *Recorder) SpanOfKind(name string, kind traceCore.SpanKind) traceSdk.ReadOnlySpan {
+	r.t.Helper()
+	if span := r.lastEnded(name, &kind); span != nil {
+		return span
+	}
+	r.t.Fatalf("no ended %s span named %q", kind, name)
+	return nil
+}
+
+func (r *Recorder) lastEnded(name string, kind *traceCore.SpanKind) traceSdk.ReadOnlySpan {
+	ended := r.recorder.Ended()
+	for i := len(ended) - 1; i >= 0; i-- {
+		if ended[i].Name() == name && (kind == nil || ended[i].SpanKind() == *kind) {
+			return ended[i]
+		}
+	}
+	return nil
+}
+
+// Attributes returns the attributes of span, started or ended, by key.
+func (r *Recorder) Attributes(span any) map[attribute.Key]attribute.Value {
+	r.t.Helper()
+	attributes, _ := r.attributes(span)
+	byKey := make(map[attribute.Key]attribute.Value, len(attributes))
+	for _, kv := range attributes {
+		byKey[kv.Key] = kv.Value
+	}
+	return byKey
+}
+
+// AssertTransaction asserts that span, started or ended, belongs to
+// transaction.
+func (r *Recorder) AssertTransaction(span any, transaction string) *Recorder {
+	r.t.Helper()
+	r.assertAttribute(span, sampler.TransactionIdentifier, attribute.StringValue(transaction))
+	return r
+}
+
+// AssertDistributed asserts that span, started or ended, belongs to the
+// distributed transaction transaction.
+func (r *Recorder) AssertDistributed(span any, transaction string) *Recorder {
+	r.t.Helper()
+	r.assertAttribute(span, sampler.DistributedTransactionIdentifier, attribute.StringValue(transaction))
+	return r
+}
+
+// AssertRoot asserts that span, started or ended, starts its transaction.
+func (r *Recorder) AssertRoot(span any) *Recorder {
+	r.t.Helper()
+	r.assertAttribute(span, sampler.TransactionIdentifierRoot, attribute.BoolValue(true))
+	return r
+}
+
+// AssertNotRoot asserts that span, started or ended, continues the
+// transaction of its parent.
+func (r *Recorder) AssertNotRoot(span any) *Recorder {
+	r.t.Helper()
+	if attributes, ok := r.attributes(span); ok && isRoot(attributes) {
+		r.t.Errorf("span %q starts transaction %q", spanName(span), attributeValue(attributes, sampler.TransactionIdentifier).Emit())
+	}
+	return r
+}
+
+func (r *Recorder) assertAttribute(span any, key attribute.Key, expected attribute.Value) {
+	r.t.Helper()
+	attributes, ok := r.attributes(span)
+	if !ok {
+		return
+	}
+	if actual := attributeValue(attributes, key); actual != expected {
+		r.t.Errorf("span %q: %s is %q, expected %q", spanName(span), key, actual.Emit(), expected.Emit())
+	}
+}
+
+// attributes returns the attributes of span, which is either a span of the
+// SDK or a ReadOnlySpan.
+func (r *Recorder) attributes(span any) ([]attribute.KeyValue, bool) {
+	r.t.Helper()
+	s, ok := span.(readOnlySpan)
+	if !ok {
+		r.t.Errorf("%T is not a span recorded by the SDK", span)
+		return nil, false
+	}
+	return s.Attributes(), true
+}
+
+// readOnlySpan is implemented by both the started and the ended spans of the
+// SDK.
+type readOnlySpan interface {
+	Name() string
+	Attributes() []attribute.KeyValue
+}
+
+func spanName(span any) string {
+	return span.(readOnlySpan).Name()
+}
+
+func attributeValue(attributes []attribute.KeyValue, key attribute.Key) attribute.Value {
+	for _, kv := range attributes {
+		if kv.Key == key {
+			return kv.Value
+		}
+	}
+	return attribute.Value{}
+}
+
+func isRoot(attributes []attribute.KeyValue) bool {
+	return attributeValue(attributes, sampler.TransactionIdentifierRoot).AsBool()
+}
@@ -0,0 +1,111 @@
+package cgxtest
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/propagation"
+	traceCore "go.opentelemetry.io/otel/trace"
+
+	"github.com/coralogix/coralogix-opentelemetry-go/sampler"
+)
+
+// failures records the failures of assertions instead of failing the test.
+type failures struct {
+	testing.TB
+	errors []string
+}
+
+func (f *failures) Helper() {}
+
+func (f *failures) Errorf(format string, args ...any) {
+	f.errors = append(f.errors, fmt.Sprintf(format, args...))
+}
+
+func TestRecorder_Assertions(t *testing.T) {
+	recorder := NewRecorder(t)
+	tracer := recorder.Tracer("test")
+	ctx, server := tracer.Start(context.Background(), "POST /orders", traceCore.WithSpanKind(traceCore.SpanKindServer))
+	ctx, flow := tracer.Start(ctx, "flow1")
+	sampler.StartNewTransaction(flow, "flow1")
+	_, child := tracer.Start(ctx, "subFlow1")
+
+	recorder.AssertTransaction(server, "POST /orders").AssertRoot(server).AssertDistributed(server, "POST /orders")
+	recorder.AssertTransaction(flow, "flow1").AssertRoot(flow).AssertDistributed(flow, "POST /orders")
+	recorder.AssertTransaction(child, "flow1").AssertNotRoot(child).AssertDistributed(child, "POST /orders")
+
+	child.End()
+	flow.End()
+	server.End()
+	recorder.AssertTransaction(recorder.Span("subFlow1"), "flow1").AssertNotRoot(recorder.Span("subFlow1"))
+	assert.Len(t, recorder.Ended(), 3)
+	assert.Equal(t, "flow1", recorder.Attributes(child)[sampler.TransactionIdentifier].AsString())
+	assert.Equal(t, server.SpanContext(), recorder.SpanOfKind("POST /orders", traceCore.SpanKindServer).SpanContext())
+}
+
+func TestRecorder_AssertionFailures(t *testing.T) {
+	recorder := NewRecorder(t)
+	f := &failures{}
+	recorder.t = f
+	_, span := recorder.Tracer("test").Start(context.Background(), "GET /users")
+
+	recorder.AssertTransaction(span, "flow1").AssertNotRoot(span).AssertRoot(traceCore.SpanFromContext(context.Background()))
+
+	assert.Equal(t, []string{
+		`span "GET /users": cgx.transaction is "GET /users", expected "flow1"`,
+		`span "GET /users" starts transaction "GET /users"`,
+		`trace.noopSpan is not a span recorded by the SDK`,
+	}, f.errors)
+}
+
+func TestRecorder_TransactionTree(t *testing.T) {
+	recorder := NewRecorder(t)
+	tracer := recorder.Tracer("test")
+	ctx, server := tracer.Start(context.Background(), "POST /orders", traceCore.WithSpanKind(traceCore.SpanKindServer))
+	for _, name := range []string{"flow1", "flow2"} {
+		flowCtx, flow := tracer.Start(ctx, name)
+		sampler.StartNewTransaction(flow, name)
+		_, child := tracer.Start(flowCtx, "sub"+name)
+		child.End()
+		flow.End()
+	}
+	_, query := tracer.Start(ctx, "SELECT orders", traceCore.WithSpanKind(traceCore.SpanKindClient))
+	query.End()
+
+	// a downstream service continuing the trace, recorded by the same recorder
+	carrier := propagation.MapCarrier{}
+	propagation.TraceContext{}.Inject(ctx, carrier)
+	remoteCtx := propagation.TraceContext{}.Extract(context.Background(), carrier)
+	_, consumer := tracer.Start(remoteCtx, "orders process", traceCore.WithSpanKind(traceCore.SpanKindConsumer))
+	consumer.End()
+	server.End()
+
+	tree := recorder.TransactionTree()
+	assert.Equal(t, "POST /orders\n  flow1\n  flow2\n  orders process\n", tree.String())
+	require.Len(t, tree, 1)
+	assert.Equal(t, server.SpanContext(), tree[0].Span.SpanContext())
+	assert.Len(t, tree[0].Spans, 2)
+	require.Len(t, tree[0].Children, 3)
+	assert.Len(t, tree[0].Children[1].Spans, 2)
+	downstream := tree[0].Children[2]
+	assert.Equal(t, "orders process", downstream.Name)
+	assert.Equal(t, "POST /orders", downstream.Distributed)
+}
+
+func TestRecorder_TransactionTree_Traces(t *testing.T) {
+	recorder := NewRecorder(t)
+	tracer := recorder.Tracer("test")
+	for _, name := range []string{"GET /orders", "GET /users"} {
+		ctx, server := tracer.Start(context.Background(), name, traceCore.WithSpanKind(traceCore.SpanKindServer))
+		_, child := tracer.Start(ctx, "load")
+		child.End()
+		server.End()
+	}
+
+	tree := recorder.TransactionTree()
+	assert.Equal(t, "GET /orders\nGET /users\n", tree.String())
+	assert.Equal(t, "load", tree[1].Spans[1].Name())
+}
@@ -0,0 +1,99 @@
+package cgxtest
+
+import (
+	"sort"
+	"strings"
+
+	traceSdk "go.opentelemetry.io/otel/sdk/trace"
+	traceCore "go.opentelemetry.io/otel/trace"
+
+	"github.com/coralogix/coralogix-opentelemetry-go/sampler"
+)
+
+// Transaction is a transaction of the recorded spans.
+type Transaction struct {
+	Name        string
+	Distributed string
+	// Span is the span that started the transaction.
+	Span traceSdk.ReadOnlySpan
+	// Spans are the spans of the transaction, Span included, in the order
+	// they started.
+	Spans []traceSdk.ReadOnlySpan
+	// Children are the transactions started within the transaction.
+	Children Tree
+}
+
+// Tree is a forest of transactions, in the order they started.
+type Tree []*Transaction
+
+// String renders the names of the transactions, one per line, with the
+// children of a transaction indented under it:
+//
+//	POST /orders
+//	  flow1
+//	  flow2
+func (t Tree) String() string {
+	var b strings.Builder
+	t.write(&b, 0)
+	return b.String()
+}
+
+func (t Tree) write(b *strings.Builder, depth int) {
+	for _, transaction := range t {
+		b.WriteString(strings.Repeat("  ", depth))
+		b.WriteString(transaction.Name)
+		b.WriteByte('\n')
+		transaction.Children.write(b, depth+1)
+	}
+}
+
+// TransactionTree groups the ended spans by transaction. A span starts a
+// transaction if it is a transaction root, or if its parent was not recorded,
+// and belongs to the transaction of its parent otherwise. A transaction is the
+// child of the transaction of the parent of the span that started it.
+func (r *Recorder) TransactionTree() Tree {
+	ended := append([]traceSdk.ReadOnlySpan(nil), r.recorder.Ended()...)
+	sort.SliceStable(ended, func(i, j int) bool {
+		return ended[i].StartTime().Before(ended[j].StartTime())
+	})
+	spans := make(map[traceCore.SpanID]traceSdk.ReadOnlySpan, len(ended))
+	for _, span := range ended {
+		spans[span.SpanContext().SpanID()] = span
+	}
+
+	transactions := map[traceCore.SpanID]*Transaction{}
+	var owner func(span traceSdk.ReadOnlySpan) *Transaction
+	owner = func(span traceSdk.ReadOnlySpan) *Transaction {
+		id := span.SpanContext().SpanID()
+		if transaction, ok := transactions[id]; ok {
+			return transaction
+		}
+		parent, recorded := spans[span.Parent().SpanID()]
+		if recorded && !isRoot(span.Attributes()) {
+			transactions[id] = owner(parent)
+			return transactions[id]
+		}
+		transactions[id] = &Transaction{
+			Name:        attributeValue(span.Attributes(), sampler.TransactionIdentifier).AsString(),
+			Distributed: attributeValue(span.Attributes(), sampler.DistributedTransactionIdentifier).AsString(),
+			Span:        span,
+		}
+		return transactions[id]
+	}
+
+	var tree Tree
+	for _, span := range ended {
+		transaction := owner(span)
+		transaction.Spans = append(transaction.Spans, span)
+		if transaction.Span != span {
+			continue
+		}
+		if parent, ok := spans[span.Parent().SpanID()]; ok {
+			parentTransaction := owner(parent)
+			parentTransaction.Children = append(parentTransaction.Children, transaction)
+		} else {
+			tree = append(tree, transaction)
+		}
+	}
+	return tree
+}
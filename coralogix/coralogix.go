@@ -0,0 +1,176 @@
+// Package coralogix bootstraps the OpenTelemetry pipelines that export to
+// Coralogix. Every provider is configured with the same options: the ingress
+// endpoint, the send-your-data key and the application and subsystem the
+// telemetry is reported under.
+package coralogix
+
+import (
+	"errors"
+	"os"
+
+	"go.opentelemetry.io/otel/attribute"
+	metricSdk "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/resource"
+	traceSdk "go.opentelemetry.io/otel/sdk/trace"
+)
+
+const (
+	ApplicationNameAttribute = "cx.application.name"
+	SubsystemNameAttribute   = "cx.subsystem.name"
+
+	AuthorizationHeader   = "Authorization"
+	ApplicationNameHeader = "CX-Application-Name"
+	SubsystemNameHeader   = "CX-Subsystem-Name"
+
+	// The environment variables the options default to.
+	EndpointEnv        = "CX_ENDPOINT"
+	PrivateKeyEnv      = "CX_TOKEN"
+	ApplicationNameEnv = "CX_APPLICATION_NAME"
+	SubsystemNameEnv   = "CX_SUBSYSTEM_NAME"
+)
+
+var (
+	ErrMissingEndpoint   = errors.New("coralogix: missing endpoint")
+	ErrMissingPrivateKey = errors.New("coralogix: missing private key")
+)
+
+type config struct {
+	endpoint        string
+	privateKey      string
+	applicationName string
+	subsystemName   string
+	insecure        bool
+	attributes      []attribute.KeyValue
+	sampler         traceSdk.Sampler
+
+	deltaTemporality      bool
+	exponentialHistograms bool
+	views                 []metricSdk.View
+}
+
+type Option func(*config)
+
+// WithEndpoint sets the host and port of the Coralogix OTLP ingress of the
+// account's domain, e.g. ingress.eu2.coralogix.com:443, CX_ENDPOINT by default.
+func WithEndpoint(endpoint string) Option {
+	return func(c *config) {
+		c.endpoint = endpoint
+	}
+}
+
+// WithPrivateKey sets the send-your-data API key, CX_TOKEN by default.
+func WithPrivateKey(key string) Option {
+	return func(c *config) {
+		c.privateKey = key
+	}
+}
+
+// WithApplicationName sets the application the telemetry is reported under,
+// CX_APPLICATION_NAME by default.
+func WithApplicationName(name string) Option {
+	return func(c *config) {
+		c.applicationName = name
+	}
+}
+
+// WithSubsystemName sets the subsystem the telemetry is reported under,
+// CX_SUBSYSTEM_NAME by default.
+func WithSubsystemName(name string) Option {
+	return func(c *config) {
+		c.subsystemName = name
+	}
+}
+
+// WithInsecure exports without TLS, to a local collector for instance.
+func WithInsecure() Option {
+	return func(c *config) {
+		c.insecure = true
+	}
+}
+
+// WithAttributes adds attributes to the resource telemetry is reported with,
+// e.g. service.name.
+func WithAttributes(attributes ...attribute.KeyValue) Option {
+	return func(c *config) {
+		c.attributes = append(c.attributes, attributes...)
+	}
+}
+
+// WithSampler sets the sampler the CoralogixSampler of the tracer provider
+// wraps, parent based always on by default.
+func WithSampler(sampler traceSdk.Sampler) Option {
+	return func(c *config) {
+		c.sampler = sampler
+	}
+}
+
+// WithDeltaTemporality exports counters and histograms of the meter provider
+// with delta temporality, rather than cumulative.
+func WithDeltaTemporality() Option {
+	return func(c *config) {
+		c.deltaTemporality = true
+	}
+}
+
+// WithExponentialHistograms aggregates the histograms of the meter provider
+// into base2 exponential histograms, rather than explicit bucket ones.
+func WithExponentialHistograms() Option {
+	return func(c *config) {
+		c.exponentialHistograms = true
+	}
+}
+
+// WithViews sets the views of the meter provider. An instrument is only
+// aggregated by the first view matching it.
+func WithViews(views ...metricSdk.View) Option {
+	return func(c *config) {
+		c.views = append(c.views, views...)
+	}
+}
+
+func newConfig(options []Option) (config, error) {
+	c := config{
+		endpoint:        os.Getenv(EndpointEnv),
+		privateKey:      os.Getenv(PrivateKeyEnv),
+		applicationName: os.Getenv(ApplicationNameEnv),
+		subsystemName:   os.Getenv(SubsystemNameEnv),
+		sampler:         traceSdk.ParentBased(traceSdk.AlwaysSample()),
+	}
+	for _, option := range options {
+		option(&c)
+	}
+	if c.endpoint == "" {
+		return config{}, ErrMissingEndpoint
+	}
+	if c.privateKey == "" {
+		return config{}, ErrMissingPrivateKey
+	}
+	return c, nil
+}
+
+// headers authenticate the exports and route them to the application and
+// subsystem.
+func (c config) headers() map[string]string {
+	headers := map[string]string{AuthorizationHeader: "Bearer " + c.privateKey}
+	if c.applicationName != "" {
+		headers[ApplicationNameHeader] = c.applicationName
+	}
+	if c.subsystemName != "" {
+		headers[SubsystemNameHeader] = c.subsystemName
+	}
+	return headers
+}
+
+// resource is the default resource with the application, the subsystem and
+// the attributes of the config.
+func (c config) resource() (*resource.Resource, error) {
+	attributes := make([]attribute.KeyValue, 0, len(c.attributes)+2)
+	if c.applicationName != "" {
+		attributes = append(attributes, attribute.String(ApplicationNameAttribute, c.applicationName))
+	}
+	if c.subsystemName != "" {
+		attributes = append(attributes, attribute.String(SubsystemNameAttribute, c.subsystemName))
+	}
+	attributes = append(attributes, c.attributes...)
+	return resource.Merge(resource.Default(), resource.NewSchemaless(attributes...))
+}
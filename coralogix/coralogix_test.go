@@ -0,0 +1,153 @@
+package coralogix
+
+import (
+	"context"
+	"net"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	collectorLogs "go.opentelemetry.io/proto/otlp/collector/logs/v1"
+	collectorMetrics "go.opentelemetry.io/proto/otlp/collector/metrics/v1"
+	collectorTrace "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	common "go.opentelemetry.io/proto/otlp/common/v1"
+	resourceProto "go.opentelemetry.io/proto/otlp/resource/v1"
+	"google.golang.org/grpc"
+	_ "google.golang.org/grpc/encoding/gzip"
+	"google.golang.org/grpc/metadata"
+)
+
+// receiver stands in for the Coralogix OTLP ingress.
+type receiver struct {
+	collectorTrace.UnimplementedTraceServiceServer
+
+	mu       sync.Mutex
+	metadata []metadata.MD
+	traces   []*collectorTrace.ExportTraceServiceRequest
+	logs     []*collectorLogs.ExportLogsServiceRequest
+	metrics  []*collectorMetrics.ExportMetricsServiceRequest
+}
+
+func (r *receiver) Export(ctx context.Context, request *collectorTrace.ExportTraceServiceRequest) (*collectorTrace.ExportTraceServiceResponse, error) {
+	r.record(ctx)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.traces = append(r.traces, request)
+	return &collectorTrace.ExportTraceServiceResponse{}, nil
+}
+
+func (r *receiver) record(ctx context.Context) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.metadata = append(r.metadata, md)
+}
+
+// logsService registers the receiver as a logs service, whose Export method
+// clashes with the trace service one.
+type logsService struct {
+	collectorLogs.UnimplementedLogsServiceServer
+	*receiver
+}
+
+func (s logsService) Export(ctx context.Context, request *collectorLogs.ExportLogsServiceRequest) (*collectorLogs.ExportLogsServiceResponse, error) {
+	s.record(ctx)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.logs = append(s.logs, request)
+	return &collectorLogs.ExportLogsServiceResponse{}, nil
+}
+
+// metricsService registers the receiver as a metrics service.
+type metricsService struct {
+	collectorMetrics.UnimplementedMetricsServiceServer
+	*receiver
+}
+
+func (s metricsService) Export(ctx context.Context, request *collectorMetrics.ExportMetricsServiceRequest) (*collectorMetrics.ExportMetricsServiceResponse, error) {
+	s.record(ctx)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.metrics = append(s.metrics, request)
+	return &collectorMetrics.ExportMetricsServiceResponse{}, nil
+}
+
+// startReceiver listens on a local port and returns the options exporting to it.
+func startReceiver(t *testing.T) (*receiver, []Option) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	r := &receiver{}
+	server := grpc.NewServer()
+	collectorTrace.RegisterTraceServiceServer(server, r)
+	collectorLogs.RegisterLogsServiceServer(server, logsService{receiver: r})
+	collectorMetrics.RegisterMetricsServiceServer(server, metricsService{receiver: r})
+	go func() { _ = server.Serve(listener) }()
+	t.Cleanup(server.Stop)
+	return r, []Option{
+		WithEndpoint(listener.Addr().String()),
+		WithInsecure(),
+		WithPrivateKey("cxtp_key"),
+		WithApplicationName("shop"),
+		WithSubsystemName("checkout"),
+	}
+}
+
+func (r *receiver) assertMetadata(t *testing.T) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	require.NotEmpty(t, r.metadata)
+	for _, md := range r.metadata {
+		assert.Equal(t, []string{"Bearer cxtp_key"}, md.Get(AuthorizationHeader))
+		assert.Equal(t, []string{"shop"}, md.Get(ApplicationNameHeader))
+		assert.Equal(t, []string{"checkout"}, md.Get(SubsystemNameHeader))
+	}
+}
+
+func attributeMap(attributes []*common.KeyValue) map[string]*common.AnyValue {
+	values := map[string]*common.AnyValue{}
+	for _, kv := range attributes {
+		values[kv.Key] = kv.Value
+	}
+	return values
+}
+
+func assertResource(t *testing.T, res *resourceProto.Resource) {
+	attributes := attributeMap(res.Attributes)
+	assert.Equal(t, "shop", attributes[ApplicationNameAttribute].GetStringValue())
+	assert.Equal(t, "checkout", attributes[SubsystemNameAttribute].GetStringValue())
+	assert.Equal(t, "go", attributes["telemetry.sdk.language"].GetStringValue())
+}
+
+func TestNewConfig(t *testing.T) {
+	t.Setenv(EndpointEnv, "ingress.eu2.coralogix.com:443")
+	t.Setenv(PrivateKeyEnv, "cxtp_env")
+	t.Setenv(ApplicationNameEnv, "shop")
+	t.Setenv(SubsystemNameEnv, "")
+
+	c, err := newConfig([]Option{WithSubsystemName("checkout")})
+	require.NoError(t, err)
+	assert.Equal(t, "ingress.eu2.coralogix.com:443", c.endpoint)
+	assert.Equal(t, map[string]string{
+		AuthorizationHeader:   "Bearer cxtp_env",
+		ApplicationNameHeader: "shop",
+		SubsystemNameHeader:   "checkout",
+	}, c.headers())
+}
+
+func TestNewConfig_Missing(t *testing.T) {
+	t.Setenv(EndpointEnv, "")
+	t.Setenv(PrivateKeyEnv, "")
+
+	_, err := newConfig(nil)
+	assert.ErrorIs(t, err, ErrMissingEndpoint)
+	_, err = newConfig([]Option{WithEndpoint("localhost:4317")})
+	assert.ErrorIs(t, err, ErrMissingPrivateKey)
+
+	_, err = NewTracerProvider(context.Background())
+	assert.ErrorIs(t, err, ErrMissingEndpoint)
+	_, err = NewLoggerProvider(context.Background())
+	assert.ErrorIs(t, err, ErrMissingEndpoint)
+	_, err = NewMeterProvider(context.Background())
+	assert.ErrorIs(t, err, ErrMissingEndpoint)
+}
@@ -0,0 +1,53 @@
+package coralogix
+
+import (
+	"context"
+	"log/slog"
+
+	"go.opentelemetry.io/contrib/bridges/otelslog"
+	"go.opentelemetry.io/otel/exporters/otlp/otlplog/otlploggrpc"
+	"go.opentelemetry.io/otel/log"
+	logSdk "go.opentelemetry.io/otel/sdk/log"
+
+	"github.com/coralogix/coralogix-opentelemetry-go/logging/slogcorrelation"
+)
+
+// NewLoggerProvider returns a logger provider batching log records to
+// Coralogix, configured with the same options as NewTracerProvider. Shut it
+// down to flush the last records.
+func NewLoggerProvider(ctx context.Context, options ...Option) (*logSdk.LoggerProvider, error) {
+	c, err := newConfig(options)
+	if err != nil {
+		return nil, err
+	}
+	res, err := c.resource()
+	if err != nil {
+		return nil, err
+	}
+	exporterOptions := []otlploggrpc.Option{
+		otlploggrpc.WithEndpoint(c.endpoint),
+		otlploggrpc.WithHeaders(c.headers()),
+		otlploggrpc.WithCompressor("gzip"),
+	}
+	if c.insecure {
+		exporterOptions = append(exporterOptions, otlploggrpc.WithInsecure())
+	}
+	exporter, err := otlploggrpc.New(ctx, exporterOptions...)
+	if err != nil {
+		return nil, err
+	}
+	return logSdk.NewLoggerProvider(
+		logSdk.WithResource(res),
+		logSdk.WithProcessor(logSdk.NewBatchProcessor(exporter)),
+	), nil
+}
+
+// NewSlogHandler bridges log/slog to the OTel Logs API of provider, under the
+// instrumentation scope name. Records logged with the context of a span carry
+// its trace context, and the cgx.transaction fields of slogcorrelation:
+//
+//	logger := slog.New(coralogix.NewSlogHandler("checkout", provider))
+//	logger.InfoContext(ctx, "order saved", "order", order.ID)
+func NewSlogHandler(name string, provider log.LoggerProvider) slog.Handler {
+	return slogcorrelation.NewHandler(otelslog.NewHandler(name, otelslog.WithLoggerProvider(provider)))
+}
@@ -0,0 +1,52 @@
+package coralogix
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	traceSdk "go.opentelemetry.io/otel/sdk/trace"
+	traceCore "go.opentelemetry.io/otel/trace"
+
+	"github.com/coralogix/coralogix-opentelemetry-go/sampler"
+)
+
+func TestNewLoggerProvider(t *testing.T) {
+	r, options := startReceiver(t)
+	ctx := context.Background()
+	provider, err := NewLoggerProvider(ctx, options...)
+	require.NoError(t, err)
+	logger := slog.New(NewSlogHandler("checkout", provider))
+	tracer := traceSdk.NewTracerProvider(traceSdk.WithSampler(sampler.NewCoralogixSampler(traceSdk.AlwaysSample()))).Tracer("test")
+	spanCtx, span := tracer.Start(ctx, "POST /orders", traceCore.WithSpanKind(traceCore.SpanKindServer))
+
+	logger.InfoContext(spanCtx, "order saved", "order", 7)
+	logger.WarnContext(ctx, "stock low")
+	span.End()
+	require.NoError(t, provider.Shutdown(ctx))
+
+	r.assertMetadata(t)
+	require.Len(t, r.logs, 1)
+	resourceLogs := r.logs[0].ResourceLogs[0]
+	assertResource(t, resourceLogs.Resource)
+	scopeLogs := resourceLogs.ScopeLogs[0]
+	assert.Equal(t, "checkout", scopeLogs.Scope.Name)
+	require.Len(t, scopeLogs.LogRecords, 2)
+
+	saved := scopeLogs.LogRecords[0]
+	assert.Equal(t, "order saved", saved.Body.GetStringValue())
+	assert.Equal(t, "INFO", saved.SeverityText)
+	traceID, spanID := span.SpanContext().TraceID(), span.SpanContext().SpanID()
+	assert.Equal(t, traceID[:], saved.TraceId)
+	assert.Equal(t, spanID[:], saved.SpanId)
+	attributes := attributeMap(saved.Attributes)
+	assert.Equal(t, int64(7), attributes["order"].GetIntValue())
+	assert.Equal(t, "POST /orders", attributes[sampler.TransactionIdentifier].GetStringValue())
+
+	low := scopeLogs.LogRecords[1]
+	assert.Equal(t, "stock low", low.Body.GetStringValue())
+	assert.Empty(t, low.TraceId)
+	assert.NotContains(t, attributeMap(low.Attributes), sampler.TransactionIdentifier)
+}
@@ -0,0 +1,116 @@
+package coralogix
+
+import (
+	"context"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetricgrpc"
+	metricSdk "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/exemplar"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	traceCore "go.opentelemetry.io/otel/trace"
+
+	"github.com/coralogix/coralogix-opentelemetry-go/sampler"
+)
+
+// NewMeterProvider returns a meter provider periodically exporting metrics to
+// Coralogix, configured with the same options as NewTracerProvider. Shut it
+// down to export the last measurements.
+//
+// Measurements made within sampled spans are kept as exemplars, tagged with
+// the trace and span ids and the cgx.transaction of the span, so that a
+// metric spike leads to its transactions.
+func NewMeterProvider(ctx context.Context, options ...Option) (*metricSdk.MeterProvider, error) {
+	c, err := newConfig(options)
+	if err != nil {
+		return nil, err
+	}
+	res, err := c.resource()
+	if err != nil {
+		return nil, err
+	}
+	exporterOptions := []otlpmetricgrpc.Option{
+		otlpmetricgrpc.WithEndpoint(c.endpoint),
+		otlpmetricgrpc.WithHeaders(c.headers()),
+		otlpmetricgrpc.WithCompressor("gzip"),
+		otlpmetricgrpc.WithTemporalitySelector(c.temporality),
+		otlpmetricgrpc.WithAggregationSelector(c.aggregation),
+	}
+	if c.insecure {
+		exporterOptions = append(exporterOptions, otlpmetricgrpc.WithInsecure())
+	}
+	exporter, err := otlpmetricgrpc.New(ctx, exporterOptions...)
+	if err != nil {
+		return nil, err
+	}
+	return metricSdk.NewMeterProvider(
+		metricSdk.WithResource(res),
+		metricSdk.WithReader(metricSdk.NewPeriodicReader(exporter)),
+		metricSdk.WithExemplarFilter(exemplar.TraceBasedFilter),
+		metricSdk.WithView(transactionView(c.views)),
+	), nil
+}
+
+// temporality is cumulative, or delta for the instruments whose sums are
+// monotonic with WithDeltaTemporality.
+func (c config) temporality(kind metricSdk.InstrumentKind) metricdata.Temporality {
+	if !c.deltaTemporality {
+		return metricdata.CumulativeTemporality
+	}
+	switch kind {
+	case metricSdk.InstrumentKindCounter, metricSdk.InstrumentKindObservableCounter, metricSdk.InstrumentKindHistogram:
+		return metricdata.DeltaTemporality
+	}
+	return metricdata.CumulativeTemporality
+}
+
+// aggregation is the default one, but for histograms with
+// WithExponentialHistograms.
+func (c config) aggregation(kind metricSdk.InstrumentKind) metricSdk.Aggregation {
+	if c.exponentialHistograms && kind == metricSdk.InstrumentKindHistogram {
+		return metricSdk.AggregationBase2ExponentialHistogram{MaxSize: 160, MaxScale: 20}
+	}
+	return metricSdk.DefaultAggregationSelector(kind)
+}
+
+// transactionView applies the first of views matching an instrument, and
+// tags the exemplars of every instrument with their transaction. Views can't
+// be layered: each matching view adds a stream.
+func transactionView(views []metricSdk.View) metricSdk.View {
+	return func(instrument metricSdk.Instrument) (metricSdk.Stream, bool) {
+		stream := metricSdk.Stream{Name: instrument.Name, Description: instrument.Description, Unit: instrument.Unit}
+		for _, view := range views {
+			if matched, ok := view(instrument); ok {
+				stream = matched
+				break
+			}
+		}
+		selector := stream.ExemplarReservoirProviderSelector
+		if selector == nil {
+			selector = metricSdk.DefaultExemplarReservoirProviderSelector
+		}
+		stream.ExemplarReservoirProviderSelector = func(aggregation metricSdk.Aggregation) exemplar.ReservoirProvider {
+			provider := selector(aggregation)
+			return func(attributes attribute.Set) exemplar.Reservoir {
+				return transactionReservoir{Reservoir: provider(attributes)}
+			}
+		}
+		return stream, true
+	}
+}
+
+// transactionReservoir adds the cgx.transaction of the span a measurement is
+// made in to the attributes of its exemplar.
+type transactionReservoir struct {
+	exemplar.Reservoir
+}
+
+func (r transactionReservoir) Offer(ctx context.Context, t time.Time, value exemplar.Value, attributes []attribute.KeyValue) {
+	transaction := traceCore.SpanContextFromContext(ctx).TraceState().Get(sampler.TransactionIdentifierTraceState)
+	if transaction != "" {
+		// the attributes belong to the caller
+		attributes = append(attributes[:len(attributes):len(attributes)], attribute.String(sampler.TransactionIdentifier, transaction))
+	}
+	r.Reservoir.Offer(ctx, t, value, attributes)
+}
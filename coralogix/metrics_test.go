@@ -0,0 +1,92 @@
+package coralogix
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metricSdk "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	traceSdk "go.opentelemetry.io/otel/sdk/trace"
+	traceCore "go.opentelemetry.io/otel/trace"
+	metricsProto "go.opentelemetry.io/proto/otlp/metrics/v1"
+
+	"github.com/coralogix/coralogix-opentelemetry-go/sampler"
+)
+
+func TestNewMeterProvider(t *testing.T) {
+	r, options := startReceiver(t)
+	ctx := context.Background()
+	provider, err := NewMeterProvider(ctx, options...)
+	require.NoError(t, err)
+	histogram, err := provider.Meter("test").Float64Histogram("http.server.request.duration")
+	require.NoError(t, err)
+	tracer := traceSdk.NewTracerProvider(traceSdk.WithSampler(sampler.NewCoralogixSampler(traceSdk.AlwaysSample()))).Tracer("test")
+	spanCtx, span := tracer.Start(ctx, "GET /orders", traceCore.WithSpanKind(traceCore.SpanKindServer))
+
+	histogram.Record(spanCtx, 0.25)
+	histogram.Record(ctx, 0.5)
+	span.End()
+	require.NoError(t, provider.Shutdown(ctx))
+
+	r.assertMetadata(t)
+	require.Len(t, r.metrics, 1)
+	resourceMetrics := r.metrics[0].ResourceMetrics[0]
+	assertResource(t, resourceMetrics.Resource)
+	metric := resourceMetrics.ScopeMetrics[0].Metrics[0]
+	assert.Equal(t, "http.server.request.duration", metric.Name)
+	require.NotNil(t, metric.GetHistogram())
+	assert.Equal(t, metricsProto.AggregationTemporality_AGGREGATION_TEMPORALITY_CUMULATIVE, metric.GetHistogram().AggregationTemporality)
+	point := metric.GetHistogram().DataPoints[0]
+	assert.Equal(t, uint64(2), point.Count)
+	require.Len(t, point.Exemplars, 1)
+	traceID, spanID := span.SpanContext().TraceID(), span.SpanContext().SpanID()
+	assert.Equal(t, traceID[:], point.Exemplars[0].TraceId)
+	assert.Equal(t, spanID[:], point.Exemplars[0].SpanId)
+	assert.Equal(t, 0.25, point.Exemplars[0].GetAsDouble())
+	assert.Equal(t, "GET /orders", attributeMap(point.Exemplars[0].FilteredAttributes)[sampler.TransactionIdentifier].GetStringValue())
+}
+
+func TestNewMeterProvider_Views(t *testing.T) {
+	r, options := startReceiver(t)
+	ctx := context.Background()
+	provider, err := NewMeterProvider(ctx, append(options,
+		WithDeltaTemporality(),
+		WithExponentialHistograms(),
+		WithViews(metricSdk.NewView(metricSdk.Instrument{Name: "orders"}, metricSdk.Stream{Name: "orders.placed"})),
+	)...)
+	require.NoError(t, err)
+	meter := provider.Meter("test")
+	counter, err := meter.Int64Counter("orders")
+	require.NoError(t, err)
+	histogram, err := meter.Float64Histogram("latency")
+	require.NoError(t, err)
+	tracer := traceSdk.NewTracerProvider(traceSdk.WithSampler(sampler.NewCoralogixSampler(traceSdk.AlwaysSample()))).Tracer("test")
+	spanCtx, span := tracer.Start(ctx, "checkout", traceCore.WithSpanKind(traceCore.SpanKindConsumer))
+
+	counter.Add(spanCtx, 3)
+	histogram.Record(spanCtx, 0.1)
+	span.End()
+	require.NoError(t, provider.Shutdown(ctx))
+
+	metrics := map[string]*metricsProto.Metric{}
+	for _, metric := range r.metrics[0].ResourceMetrics[0].ScopeMetrics[0].Metrics {
+		metrics[metric.Name] = metric
+	}
+	require.Contains(t, metrics, "orders.placed")
+	sum := metrics["orders.placed"].GetSum()
+	assert.Equal(t, metricsProto.AggregationTemporality_AGGREGATION_TEMPORALITY_DELTA, sum.AggregationTemporality)
+	assert.Equal(t, "checkout", attributeMap(sum.DataPoints[0].Exemplars[0].FilteredAttributes)[sampler.TransactionIdentifier].GetStringValue())
+	require.Contains(t, metrics, "latency")
+	require.NotNil(t, metrics["latency"].GetExponentialHistogram())
+	assert.Len(t, metrics["latency"].GetExponentialHistogram().DataPoints[0].Exemplars, 1)
+}
+
+func TestConfig_Temporality(t *testing.T) {
+	assert.Equal(t, metricdata.CumulativeTemporality, config{}.temporality(metricSdk.InstrumentKindCounter))
+	delta := config{deltaTemporality: true}
+	assert.Equal(t, metricdata.DeltaTemporality, delta.temporality(metricSdk.InstrumentKindHistogram))
+	assert.Equal(t, metricdata.CumulativeTemporality, delta.temporality(metricSdk.InstrumentKindUpDownCounter))
+	assert.Equal(t, metricdata.CumulativeTemporality, delta.temporality(metricSdk.InstrumentKindGauge))
+}
@@ -0,0 +1,51 @@
+package coralogix
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	traceSdk "go.opentelemetry.io/otel/sdk/trace"
+
+	"github.com/coralogix/coralogix-opentelemetry-go/sampler"
+)
+
+// NewTracerProvider returns a tracer provider sampling with the
+// CoralogixSampler and batching spans to Coralogix. Shut it down to flush the
+// last spans:
+//
+//	provider, err := coralogix.NewTracerProvider(ctx,
+//		coralogix.WithApplicationName("shop"),
+//		coralogix.WithSubsystemName("checkout"),
+//	)
+//	if err != nil {
+//		return err
+//	}
+//	defer provider.Shutdown(context.Background())
+//	otel.SetTracerProvider(provider)
+func NewTracerProvider(ctx context.Context, options ...Option) (*traceSdk.TracerProvider, error) {
+	c, err := newConfig(options)
+	if err != nil {
+		return nil, err
+	}
+	res, err := c.resource()
+	if err != nil {
+		return nil, err
+	}
+	exporterOptions := []otlptracegrpc.Option{
+		otlptracegrpc.WithEndpoint(c.endpoint),
+		otlptracegrpc.WithHeaders(c.headers()),
+		otlptracegrpc.WithCompressor("gzip"),
+	}
+	if c.insecure {
+		exporterOptions = append(exporterOptions, otlptracegrpc.WithInsecure())
+	}
+	exporter, err := otlptracegrpc.New(ctx, exporterOptions...)
+	if err != nil {
+		return nil, err
+	}
+	return traceSdk.NewTracerProvider(
+		traceSdk.WithResource(res),
+		traceSdk.WithSampler(sampler.NewCoralogixSampler(c.sampler)),
+		traceSdk.WithBatcher(exporter),
+	), nil
+}
@@ -0,0 +1,31 @@
+package coralogix
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	traceCore "go.opentelemetry.io/otel/trace"
+
+	"github.com/coralogix/coralogix-opentelemetry-go/sampler"
+)
+
+func TestNewTracerProvider(t *testing.T) {
+	r, options := startReceiver(t)
+	ctx := context.Background()
+	provider, err := NewTracerProvider(ctx, options...)
+	require.NoError(t, err)
+
+	_, span := provider.Tracer("test").Start(ctx, "GET /orders", traceCore.WithSpanKind(traceCore.SpanKindServer))
+	span.End()
+	require.NoError(t, provider.Shutdown(ctx))
+
+	r.assertMetadata(t)
+	require.Len(t, r.traces, 1)
+	resourceSpans := r.traces[0].ResourceSpans[0]
+	assertResource(t, resourceSpans.Resource)
+	exported := resourceSpans.ScopeSpans[0].Spans[0]
+	assert.Equal(t, "GET /orders", exported.Name)
+	assert.Equal(t, "GET /orders", attributeMap(exported.Attributes)[sampler.TransactionIdentifier].GetStringValue())
+}
@@ -0,0 +1,127 @@
+package grpctracer
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sync"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	traceCore "go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+type client struct {
+	config *config
+	tracer traceCore.Tracer
+}
+
+func newClient(options []Option) *client {
+	c := newConfig(options)
+	return &client{config: c, tracer: c.tracerProvider.Tracer(instrumentationName)}
+}
+
+// UnaryClientInterceptor starts a Client span, named after the full method,
+// per call, and injects its trace context, cgx tracestate included, into the
+// outgoing metadata.
+func UnaryClientInterceptor(options ...Option) grpc.UnaryClientInterceptor {
+	c := newClient(options)
+	return func(ctx context.Context, method string, request, reply any, conn *grpc.ClientConn, invoker grpc.UnaryInvoker, callOptions ...grpc.CallOption) error {
+		ctx, span := c.start(ctx, method)
+		err := invoker(ctx, method, request, reply, conn, callOptions...)
+		endClientSpan(span, err)
+		return err
+	}
+}
+
+// StreamClientInterceptor starts a Client span, named after the full method,
+// per stream, and injects its trace context into the outgoing metadata. The
+// span ends when the stream does, so streams must be received from until they
+// return an error, io.EOF included.
+func StreamClientInterceptor(options ...Option) grpc.StreamClientInterceptor {
+	c := newClient(options)
+	return func(ctx context.Context, desc *grpc.StreamDesc, conn *grpc.ClientConn, method string, streamer grpc.Streamer, callOptions ...grpc.CallOption) (grpc.ClientStream, error) {
+		ctx, span := c.start(ctx, method)
+		stream, err := streamer(ctx, desc, conn, method, callOptions...)
+		if err != nil {
+			endClientSpan(span, err)
+			return nil, err
+		}
+		return &clientStream{ClientStream: stream, desc: desc, span: span}, nil
+	}
+}
+
+func (c *client) start(ctx context.Context, fullMethod string) (context.Context, traceCore.Span) {
+	ctx, span := c.tracer.Start(ctx, fullMethod,
+		traceCore.WithSpanKind(traceCore.SpanKindClient),
+		traceCore.WithAttributes(methodAttributes(fullMethod)...),
+		traceCore.WithAttributes(c.config.attributes...),
+	)
+	md, ok := metadata.FromOutgoingContext(ctx)
+	if ok {
+		// the outgoing metadata of ctx must not be modified
+		md = md.Copy()
+	} else {
+		md = metadata.MD{}
+	}
+	c.config.propagator.Inject(ctx, MetadataCarrier(md))
+	return metadata.NewOutgoingContext(ctx, md), span
+}
+
+func endClientSpan(span traceCore.Span, err error) {
+	code := status.Code(err)
+	span.SetAttributes(attribute.Int(RPCGRPCStatusCodeAttribute, int(code)))
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, status.Convert(err).Message())
+	}
+	span.End()
+}
+
+// clientStream ends its span once the stream is done.
+type clientStream struct {
+	grpc.ClientStream
+	desc *grpc.StreamDesc
+	span traceCore.Span
+	once sync.Once
+}
+
+func (s *clientStream) SendMsg(m any) error {
+	err := s.ClientStream.SendMsg(m)
+	// the status of the stream is returned by RecvMsg
+	if err != nil && !errors.Is(err, io.EOF) {
+		s.end(err)
+	}
+	return err
+}
+
+func (s *clientStream) RecvMsg(m any) error {
+	err := s.ClientStream.RecvMsg(m)
+	switch {
+	case errors.Is(err, io.EOF):
+		s.end(nil)
+	case err != nil:
+		s.end(err)
+	case !s.desc.ServerStreams:
+		// a single response ends the stream
+		s.end(nil)
+	}
+	return err
+}
+
+func (s *clientStream) Header() (metadata.MD, error) {
+	md, err := s.ClientStream.Header()
+	if err != nil {
+		s.end(err)
+	}
+	return md, err
+}
+
+func (s *clientStream) end(err error) {
+	s.once.Do(func() {
+		endClientSpan(s.span, err)
+	})
+}
@@ -0,0 +1,113 @@
+// Package grpctracer traces gRPC servers and clients with interceptors. Spans
+// are named after the full method, /package.Service/Method, so that with the
+// CoralogixSampler each method served starts its own transaction, and the cgx
+// tracestate travels in the metadata so that distributed transactions stay
+// intact.
+package grpctracer
+
+import (
+	"strings"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
+	traceCore "go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc/metadata"
+)
+
+const (
+	instrumentationName = "github.com/coralogix/coralogix-opentelemetry-go/instrumentation/grpctracer"
+
+	RPCSystemAttribute         = "rpc.system"
+	RPCServiceAttribute        = "rpc.service"
+	RPCMethodAttribute         = "rpc.method"
+	RPCGRPCStatusCodeAttribute = "rpc.grpc.status_code"
+)
+
+type config struct {
+	tracerProvider traceCore.TracerProvider
+	propagator     propagation.TextMapPropagator
+	attributes     []attribute.KeyValue
+}
+
+type Option func(*config)
+
+// WithTracerProvider sets the provider of the client and server RPC spans,
+// otel.GetTracerProvider() by default.
+func WithTracerProvider(provider traceCore.TracerProvider) Option {
+	return func(c *config) {
+		c.tracerProvider = provider
+	}
+}
+
+// WithPropagator sets the propagator reading and writing the trace context in
+// gRPC metadata, W3C trace context and baggage by default. It must carry the
+// tracestate for servers to continue the distributed transaction of clients.
+func WithPropagator(propagator propagation.TextMapPropagator) Option {
+	return func(c *config) {
+		c.propagator = propagator
+	}
+}
+
+// WithAttributes adds attributes, e.g. peer.service, to the span of every RPC.
+func WithAttributes(attributes ...attribute.KeyValue) Option {
+	return func(c *config) {
+		c.attributes = append(c.attributes, attributes...)
+	}
+}
+
+func newConfig(options []Option) *config {
+	c := &config{}
+	for _, option := range options {
+		option(c)
+	}
+	if c.tracerProvider == nil {
+		c.tracerProvider = otel.GetTracerProvider()
+	}
+	if c.propagator == nil {
+		c.propagator = propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{})
+	}
+	return c
+}
+
+// MetadataCarrier adapts gRPC metadata to a propagation.TextMapCarrier.
+type MetadataCarrier metadata.MD
+
+var _ propagation.TextMapCarrier = MetadataCarrier{}
+
+func (c MetadataCarrier) Get(key string) string {
+	values := metadata.MD(c).Get(key)
+	if len(values) == 0 {
+		return ""
+	}
+	return values[0]
+}
+
+func (c MetadataCarrier) Set(key string, value string) {
+	metadata.MD(c).Set(key, value)
+}
+
+func (c MetadataCarrier) Keys() []string {
+	keys := make([]string, 0, len(c))
+	for key := range c {
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+// methodAttributes returns the attributes of a full method, e.g.
+// /package.Service/Method.
+func methodAttributes(fullMethod string) []attribute.KeyValue {
+	attributes := []attribute.KeyValue{attribute.String(RPCSystemAttribute, "grpc")}
+	service, method, ok := strings.Cut(strings.TrimPrefix(fullMethod, "/"), "/")
+	if !ok {
+		return attributes
+	}
+	if service != "" {
+		attributes = append(attributes, attribute.String(RPCServiceAttribute, service))
+	}
+	if method != "" {
+		attributes = append(attributes, attribute.String(RPCMethodAttribute, method))
+	}
+	return attributes
+}
@@ -0,0 +1,171 @@
+package grpctracer
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/codes"
+	traceSdk "go.opentelemetry.io/otel/sdk/trace"
+	traceCore "go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	grpcCodes "google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	"github.com/coralogix/coralogix-opentelemetry-go/cgxtest"
+)
+
+const (
+	checkMethod = "/grpc.health.v1.Health/Check"
+	watchMethod = "/grpc.health.v1.Health/Watch"
+)
+
+// healthServer starts a child span per call, and fails checks of the broken service.
+type healthServer struct {
+	healthpb.UnimplementedHealthServer
+	tracer traceCore.Tracer
+}
+
+func (s *healthServer) Check(ctx context.Context, request *healthpb.HealthCheckRequest) (*healthpb.HealthCheckResponse, error) {
+	_, span := s.tracer.Start(ctx, "check")
+	defer span.End()
+	switch request.Service {
+	case "broken":
+		return nil, status.Error(grpcCodes.Unavailable, "broken")
+	case "unknown":
+		return nil, status.Error(grpcCodes.NotFound, "unknown service")
+	}
+	return &healthpb.HealthCheckResponse{Status: healthpb.HealthCheckResponse_SERVING}, nil
+}
+
+func (s *healthServer) Watch(request *healthpb.HealthCheckRequest, stream healthpb.Health_WatchServer) error {
+	_, span := s.tracer.Start(stream.Context(), "watch")
+	defer span.End()
+	for i := 0; i < 2; i++ {
+		if err := stream.Send(&healthpb.HealthCheckResponse{Status: healthpb.HealthCheckResponse_SERVING}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func startTestServer(t *testing.T) (healthpb.HealthClient, *cgxtest.Recorder) {
+	recorder := cgxtest.NewRecorder(t)
+	provider := recorder.TracerProvider()
+
+	listener := bufconn.Listen(1 << 20)
+	server := grpc.NewServer(
+		grpc.UnaryInterceptor(UnaryServerInterceptor(WithTracerProvider(provider))),
+		grpc.StreamInterceptor(StreamServerInterceptor(WithTracerProvider(provider))),
+	)
+	healthpb.RegisterHealthServer(server, &healthServer{tracer: provider.Tracer("test")})
+	go func() { _ = server.Serve(listener) }()
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufconn",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithUnaryInterceptor(UnaryClientInterceptor(WithTracerProvider(provider))),
+		grpc.WithStreamInterceptor(StreamClientInterceptor(WithTracerProvider(provider))),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+	return healthpb.NewHealthClient(conn), recorder
+}
+
+func TestUnaryInterceptors(t *testing.T) {
+	client, recorder := startTestServer(t)
+	ctx, parent := recorder.Tracer("test").Start(context.Background(), "POST /orders", traceCore.WithSpanKind(traceCore.SpanKindServer))
+	_, err := client.Check(ctx, &healthpb.HealthCheckRequest{})
+	require.NoError(t, err)
+	parent.End()
+
+	clientSpan := recorder.SpanOfKind(checkMethod, traceCore.SpanKindClient)
+	serverSpan := recorder.SpanOfKind(checkMethod, traceCore.SpanKindServer)
+	child := recorder.Span("check")
+
+	assert.Equal(t, clientSpan.SpanContext().SpanID(), serverSpan.Parent().SpanID())
+	assert.True(t, serverSpan.Parent().IsRemote())
+
+	assert.Equal(t, int64(grpcCodes.OK), recorder.Attributes(clientSpan)[RPCGRPCStatusCodeAttribute].AsInt64())
+
+	serverAttributes := recorder.Attributes(serverSpan)
+	assert.Equal(t, "grpc", serverAttributes[RPCSystemAttribute].AsString())
+	assert.Equal(t, "grpc.health.v1.Health", serverAttributes[RPCServiceAttribute].AsString())
+	assert.Equal(t, "Check", serverAttributes[RPCMethodAttribute].AsString())
+	assert.Equal(t, int64(grpcCodes.OK), serverAttributes[RPCGRPCStatusCodeAttribute].AsInt64())
+
+	recorder.
+		AssertTransaction(clientSpan, "POST /orders").
+		AssertTransaction(serverSpan, checkMethod).
+		AssertDistributed(serverSpan, "POST /orders").
+		AssertRoot(serverSpan).
+		AssertTransaction(child, checkMethod)
+}
+
+func TestUnaryInterceptors_StatusCodes(t *testing.T) {
+	client, recorder := startTestServer(t)
+
+	_, err := client.Check(context.Background(), &healthpb.HealthCheckRequest{Service: "broken"})
+	require.Equal(t, grpcCodes.Unavailable, status.Code(err))
+	_, err = client.Check(context.Background(), &healthpb.HealthCheckRequest{Service: "unknown"})
+	require.Equal(t, grpcCodes.NotFound, status.Code(err))
+
+	var serverSpans, clientSpans []traceSdk.ReadOnlySpan
+	for _, span := range recorder.Ended() {
+		switch span.SpanKind() {
+		case traceCore.SpanKindServer:
+			serverSpans = append(serverSpans, span)
+		case traceCore.SpanKindClient:
+			clientSpans = append(clientSpans, span)
+		}
+	}
+	require.Len(t, serverSpans, 2)
+	require.Len(t, clientSpans, 2)
+
+	assert.Equal(t, int64(grpcCodes.Unavailable), recorder.Attributes(serverSpans[0])[RPCGRPCStatusCodeAttribute].AsInt64())
+	assert.Equal(t, codes.Error, serverSpans[0].Status().Code)
+	assert.Equal(t, int64(grpcCodes.NotFound), recorder.Attributes(serverSpans[1])[RPCGRPCStatusCodeAttribute].AsInt64())
+	assert.Equal(t, codes.Unset, serverSpans[1].Status().Code, "client errors do not fail the server span")
+	assert.Equal(t, codes.Error, clientSpans[0].Status().Code)
+	assert.Equal(t, codes.Error, clientSpans[1].Status().Code)
+}
+
+func TestStreamInterceptors(t *testing.T) {
+	client, recorder := startTestServer(t)
+	ctx, parent := recorder.Tracer("test").Start(context.Background(), "GET /status", traceCore.WithSpanKind(traceCore.SpanKindServer))
+	stream, err := client.Watch(ctx, &healthpb.HealthCheckRequest{})
+	require.NoError(t, err)
+	received := 0
+	for {
+		_, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		require.NoError(t, err)
+		received++
+	}
+	parent.End()
+	assert.Equal(t, 2, received)
+
+	clientSpan := recorder.SpanOfKind(watchMethod, traceCore.SpanKindClient)
+	serverSpan := recorder.SpanOfKind(watchMethod, traceCore.SpanKindServer)
+	child := recorder.Span("watch")
+
+	assert.Equal(t, clientSpan.SpanContext().SpanID(), serverSpan.Parent().SpanID())
+	assert.Equal(t, int64(grpcCodes.OK), recorder.Attributes(clientSpan)[RPCGRPCStatusCodeAttribute].AsInt64())
+	recorder.
+		AssertTransaction(clientSpan, "GET /status").
+		AssertTransaction(serverSpan, watchMethod).
+		AssertDistributed(serverSpan, "GET /status").
+		AssertTransaction(child, watchMethod)
+}
@@ -0,0 +1,88 @@
+package grpctracer
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	traceCore "go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	grpcCodes "google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// serverErrorCodes are the status codes that mark a server span as failed;
+// the other ones are caused by the client.
+var serverErrorCodes = map[grpcCodes.Code]bool{
+	grpcCodes.Unknown:          true,
+	grpcCodes.DeadlineExceeded: true,
+	grpcCodes.Unimplemented:    true,
+	grpcCodes.Internal:         true,
+	grpcCodes.Unavailable:      true,
+	grpcCodes.DataLoss:         true,
+}
+
+type server struct {
+	config *config
+	tracer traceCore.Tracer
+}
+
+func newServer(options []Option) *server {
+	c := newConfig(options)
+	return &server{config: c, tracer: c.tracerProvider.Tracer(instrumentationName)}
+}
+
+// UnaryServerInterceptor starts a Server span, named after the full method, per call.
+func UnaryServerInterceptor(options ...Option) grpc.UnaryServerInterceptor {
+	s := newServer(options)
+	return func(ctx context.Context, request any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		ctx, span := s.start(ctx, info.FullMethod)
+		response, err := handler(ctx, request)
+		endServerSpan(span, err)
+		return response, err
+	}
+}
+
+// StreamServerInterceptor starts a Server span, named after the full method, per stream.
+func StreamServerInterceptor(options ...Option) grpc.StreamServerInterceptor {
+	s := newServer(options)
+	return func(srv any, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, span := s.start(stream.Context(), info.FullMethod)
+		err := handler(srv, &serverStream{ServerStream: stream, ctx: ctx})
+		endServerSpan(span, err)
+		return err
+	}
+}
+
+func (s *server) start(ctx context.Context, fullMethod string) (context.Context, traceCore.Span) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	ctx = s.config.propagator.Extract(ctx, MetadataCarrier(md))
+	return s.tracer.Start(ctx, fullMethod,
+		traceCore.WithSpanKind(traceCore.SpanKindServer),
+		traceCore.WithAttributes(methodAttributes(fullMethod)...),
+		traceCore.WithAttributes(s.config.attributes...),
+	)
+}
+
+func endServerSpan(span traceCore.Span, err error) {
+	code := status.Code(err)
+	span.SetAttributes(attribute.Int(RPCGRPCStatusCodeAttribute, int(code)))
+	if err != nil {
+		span.RecordError(err)
+		if serverErrorCodes[code] {
+			span.SetStatus(codes.Error, status.Convert(err).Message())
+		}
+	}
+	span.End()
+}
+
+// serverStream hands the context of the span to stream handlers.
+type serverStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *serverStream) Context() context.Context {
+	return s.ctx
+}
@@ -0,0 +1,107 @@
+// Package httptracer traces net/http servers and clients. Server spans are
+// named after the route of the request, so that with the CoralogixSampler each
+// route starts its own transaction, and the cgx tracestate of the caller is
+// honored so that distributed transactions stay intact.
+package httptracer
+
+import (
+	"net/http"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
+	traceCore "go.opentelemetry.io/otel/trace"
+)
+
+const (
+	instrumentationName = "github.com/coralogix/coralogix-opentelemetry-go/instrumentation/httptracer"
+
+	HTTPMethodAttribute                = "http.method"
+	HTTPRouteAttribute                 = "http.route"
+	HTTPTargetAttribute                = "http.target"
+	HTTPSchemeAttribute                = "http.scheme"
+	HTTPStatusCodeAttribute            = "http.status_code"
+	HTTPRequestContentLengthAttribute  = "http.request_content_length"
+	HTTPResponseContentLengthAttribute = "http.response_content_length"
+	NetHostNameAttribute               = "net.host.name"
+	UserAgentAttribute                 = "user_agent.original"
+	HTTPURLAttribute                   = "http.url"
+	NetPeerNameAttribute               = "net.peer.name"
+	NetPeerPortAttribute               = "net.peer.port"
+
+	// DownstreamTransactionAttribute is the transaction a client request started
+	// on the server it called, as returned in the TransactionHeader.
+	DownstreamTransactionAttribute = "cgx.transaction.downstream"
+
+	// TransactionHeader is the response header servers configured
+	// WithTransactionHeader return the name of the transaction of a request in.
+	TransactionHeader = "X-Cgx-Transaction"
+)
+
+type config struct {
+	tracerProvider traceCore.TracerProvider
+	propagator     propagation.TextMapPropagator
+	attributes     []attribute.KeyValue
+	route          func(*http.Request) string
+	// transactionHeader makes handlers return the TransactionHeader.
+	transactionHeader bool
+}
+
+type Option func(*config)
+
+// WithTracerProvider sets the provider of the handler and transport spans,
+// otel.GetTracerProvider() by default.
+func WithTracerProvider(provider traceCore.TracerProvider) Option {
+	return func(c *config) {
+		c.tracerProvider = provider
+	}
+}
+
+// WithPropagator sets the propagator reading the trace context from request
+// headers and writing it into outgoing ones, W3C trace context and baggage by
+// default. A propagator dropping the tracestate splits distributed
+// transactions at every service.
+func WithPropagator(propagator propagation.TextMapPropagator) Option {
+	return func(c *config) {
+		c.propagator = propagator
+	}
+}
+
+// WithAttributes adds attributes to the span of every request served or sent.
+func WithAttributes(attributes ...attribute.KeyValue) Option {
+	return func(c *config) {
+		c.attributes = append(c.attributes, attributes...)
+	}
+}
+
+// WithRouteFunc sets how the route of a request is found, for routers other
+// than http.ServeMux, e.g. chi.RouteContext(r.Context()).RoutePattern(). An
+// empty route falls back to the ServeMux pattern.
+func WithRouteFunc(route func(*http.Request) string) Option {
+	return func(c *config) {
+		c.route = route
+	}
+}
+
+// WithTransactionHeader makes handlers return the transaction of every request
+// in the TransactionHeader, for the transports of callers to record. The header
+// reveals route names, so only enable it on services called internally.
+func WithTransactionHeader() Option {
+	return func(c *config) {
+		c.transactionHeader = true
+	}
+}
+
+func newConfig(options []Option) *config {
+	c := &config{}
+	for _, option := range options {
+		option(c)
+	}
+	if c.tracerProvider == nil {
+		c.tracerProvider = otel.GetTracerProvider()
+	}
+	if c.propagator == nil {
+		c.propagator = propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{})
+	}
+	return c
+}
@@ -0,0 +1,175 @@
+package httptracer
+
+import (
+	"bufio"
+	"net"
+	"net/http"
+	"strings"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	traceCore "go.opentelemetry.io/otel/trace"
+
+	"github.com/coralogix/coralogix-opentelemetry-go/sampler"
+)
+
+type handler struct {
+	next   http.Handler
+	config *config
+	tracer traceCore.Tracer
+}
+
+// NewHandler wraps next with a Server span per request. Spans are named
+// "METHOD route", after the route found by WithRouteFunc or the ServeMux pattern
+// that matches the request, either because next is a ServeMux or because the
+// handler was registered on one with a pattern. Requests without a route are
+// named after their method only, to keep span names low-cardinality.
+//
+// WithTransactionHeader returns the transaction of the request in the
+// TransactionHeader, for the client to record.
+func NewHandler(next http.Handler, options ...Option) http.Handler {
+	c := newConfig(options)
+	return &handler{next: next, config: c, tracer: c.tracerProvider.Tracer(instrumentationName)}
+}
+
+// Middleware returns NewHandler as a middleware, for routers that chain them.
+func Middleware(options ...Option) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return NewHandler(next, options...)
+	}
+}
+
+func (h *handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	ctx := h.config.propagator.Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+	route := h.route(r)
+	attributes := append(requestAttributes(r), h.config.attributes...)
+	if route != "" {
+		attributes = append(attributes, attribute.String(HTTPRouteAttribute, routePath(route)))
+	}
+	ctx, span := h.tracer.Start(ctx, spanName(r.Method, route),
+		traceCore.WithSpanKind(traceCore.SpanKindServer),
+		traceCore.WithAttributes(attributes...),
+	)
+	defer span.End()
+	if transaction := span.SpanContext().TraceState().Get(sampler.TransactionIdentifierTraceState); transaction != "" && h.config.transactionHeader {
+		w.Header().Set(TransactionHeader, transaction)
+	}
+
+	recorder := &responseWriter{ResponseWriter: w, status: http.StatusOK}
+	h.next.ServeHTTP(recorder, r.WithContext(ctx))
+
+	span.SetAttributes(
+		attribute.Int(HTTPStatusCodeAttribute, recorder.status),
+		attribute.Int64(HTTPResponseContentLengthAttribute, recorder.written),
+	)
+	if recorder.status >= http.StatusInternalServerError {
+		span.SetStatus(codes.Error, http.StatusText(recorder.status))
+	}
+}
+
+// route returns the route of r, which must be known before the span starts
+// since the CoralogixSampler names the transaction after the span.
+func (h *handler) route(r *http.Request) string {
+	if h.config.route != nil {
+		if route := h.config.route(r); route != "" {
+			return route
+		}
+	}
+	if r.Pattern != "" {
+		return r.Pattern
+	}
+	if mux, ok := h.next.(*http.ServeMux); ok {
+		_, pattern := mux.Handler(r)
+		return pattern
+	}
+	return ""
+}
+
+// spanName prefixes route with method, unless it is a Go 1.22 ServeMux pattern
+// that already starts with one, as in "GET /users/{id}".
+func spanName(method string, route string) string {
+	if route == "" {
+		return method
+	}
+	if strings.Contains(route, " ") {
+		return route
+	}
+	return method + " " + route
+}
+
+// routePath strips the method of a ServeMux pattern.
+func routePath(route string) string {
+	if i := strings.LastIndexByte(route, ' '); i >= 0 {
+		return strings.TrimSpace(route[i+1:])
+	}
+	return route
+}
+
+func requestAttributes(r *http.Request) []attribute.KeyValue {
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+	attributes := []attribute.KeyValue{
+		attribute.String(HTTPMethodAttribute, r.Method),
+		attribute.String(HTTPSchemeAttribute, scheme),
+		attribute.String(HTTPTargetAttribute, r.URL.Path),
+	}
+	if host := r.Host; host != "" {
+		if name, _, err := net.SplitHostPort(host); err == nil {
+			host = name
+		}
+		attributes = append(attributes, attribute.String(NetHostNameAttribute, host))
+	}
+	if r.ContentLength > 0 {
+		attributes = append(attributes, attribute.Int64(HTTPRequestContentLengthAttribute, r.ContentLength))
+	}
+	if userAgent := r.UserAgent(); userAgent != "" {
+		attributes = append(attributes, attribute.String(UserAgentAttribute, userAgent))
+	}
+	return attributes
+}
+
+// responseWriter records the status and the size of a response.
+type responseWriter struct {
+	http.ResponseWriter
+	status      int
+	written     int64
+	wroteHeader bool
+}
+
+func (w *responseWriter) WriteHeader(status int) {
+	// informational responses precede the final one
+	if !w.wroteHeader && status >= http.StatusOK {
+		w.status = status
+		w.wroteHeader = true
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *responseWriter) Write(b []byte) (int, error) {
+	w.wroteHeader = true
+	n, err := w.ResponseWriter.Write(b)
+	w.written += int64(n)
+	return n, err
+}
+
+func (w *responseWriter) Flush() {
+	w.wroteHeader = true
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+func (w *responseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	if hijacker, ok := w.ResponseWriter.(http.Hijacker); ok {
+		return hijacker.Hijack()
+	}
+	return nil, nil, http.ErrNotSupported
+}
+
+// Unwrap lets http.ResponseController reach the wrapped writer.
+func (w *responseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
@@ -0,0 +1,134 @@
+package httptracer
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/codes"
+	traceCore "go.opentelemetry.io/otel/trace"
+
+	"github.com/coralogix/coralogix-opentelemetry-go/cgxtest"
+	"github.com/coralogix/coralogix-opentelemetry-go/sampler"
+)
+
+func TestNewHandler_ServeMuxPattern(t *testing.T) {
+	recorder := cgxtest.NewRecorder(t)
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /users/{id}", func(w http.ResponseWriter, r *http.Request) {
+		_, child := recorder.Tracer("test").Start(r.Context(), "load user")
+		child.End()
+		_, _ = w.Write([]byte("alice"))
+	})
+
+	response := httptest.NewRecorder()
+	NewHandler(mux, WithTracerProvider(recorder.TracerProvider())).ServeHTTP(response, httptest.NewRequest(http.MethodGet, "/users/42", nil))
+	require.Equal(t, http.StatusOK, response.Code)
+
+	span := recorder.Span("GET /users/{id}")
+	assert.Equal(t, traceCore.SpanKindServer, span.SpanKind())
+	attributes := recorder.Attributes(span)
+	assert.Equal(t, "/users/{id}", attributes[HTTPRouteAttribute].AsString())
+	assert.Equal(t, "/users/42", attributes[HTTPTargetAttribute].AsString())
+	assert.Equal(t, int64(http.StatusOK), attributes[HTTPStatusCodeAttribute].AsInt64())
+	assert.Equal(t, int64(len("alice")), attributes[HTTPResponseContentLengthAttribute].AsInt64())
+	child := recorder.Span("load user")
+	recorder.
+		AssertTransaction(span, "GET /users/{id}").
+		AssertDistributed(span, "GET /users/{id}").
+		AssertRoot(span).
+		AssertTransaction(child, "GET /users/{id}").
+		AssertNotRoot(child)
+}
+
+func TestNewHandler_PatternWithoutMethod(t *testing.T) {
+	recorder := cgxtest.NewRecorder(t)
+	mux := http.NewServeMux()
+	mux.Handle("/health", NewHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}), WithTracerProvider(recorder.TracerProvider())))
+
+	mux.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/health", nil))
+
+	span := recorder.Span("POST /health")
+	attributes := recorder.Attributes(span)
+	assert.Equal(t, "/health", attributes[HTTPRouteAttribute].AsString())
+	assert.Equal(t, int64(http.StatusNoContent), attributes[HTTPStatusCodeAttribute].AsInt64())
+}
+
+func TestNewHandler_RouteFunc(t *testing.T) {
+	recorder := cgxtest.NewRecorder(t)
+	handler := NewHandler(http.NotFoundHandler(),
+		WithTracerProvider(recorder.TracerProvider()),
+		WithRouteFunc(func(r *http.Request) string { return "/orders/:id" }),
+	)
+
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodDelete, "/orders/7", nil))
+
+	span := recorder.Span("DELETE /orders/:id")
+	assert.Equal(t, int64(http.StatusNotFound), recorder.Attributes(span)[HTTPStatusCodeAttribute].AsInt64())
+	assert.Equal(t, codes.Unset, span.Status().Code)
+}
+
+func TestNewHandler_WithoutRoute(t *testing.T) {
+	recorder := cgxtest.NewRecorder(t)
+	handler := NewHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "boom", http.StatusInternalServerError)
+	}), WithTracerProvider(recorder.TracerProvider()))
+
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/anything/123", nil))
+
+	span := recorder.Span("GET")
+	_, ok := recorder.Attributes(span)[HTTPRouteAttribute]
+	assert.False(t, ok)
+	assert.Equal(t, codes.Error, span.Status().Code)
+}
+
+func TestNewHandler_HonorsIncomingTraceState(t *testing.T) {
+	recorder := cgxtest.NewRecorder(t)
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /checkout", func(w http.ResponseWriter, r *http.Request) {})
+
+	request := httptest.NewRequest(http.MethodPost, "/checkout", strings.NewReader("{}"))
+	request.Header.Set("traceparent", "00-5bd66ef5095369c7b0d1f8f4bd33716a-c532cb4098ac3dd2-01")
+	request.Header.Set("tracestate", "cgx_transaction=frontend,cgx_transaction_distributed=GET /cart")
+	NewHandler(mux, WithTracerProvider(recorder.TracerProvider())).ServeHTTP(httptest.NewRecorder(), request)
+
+	span := recorder.Span("POST /checkout")
+	assert.Equal(t, "5bd66ef5095369c7b0d1f8f4bd33716a", span.SpanContext().TraceID().String())
+	assert.Equal(t, "c532cb4098ac3dd2", span.Parent().SpanID().String())
+	assert.True(t, span.Parent().IsRemote())
+	recorder.AssertTransaction(span, "POST /checkout").AssertDistributed(span, "GET /cart")
+	assert.Equal(t, "POST /checkout", span.SpanContext().TraceState().Get(sampler.TransactionIdentifierTraceState))
+	assert.Equal(t, "GET /cart", span.SpanContext().TraceState().Get(sampler.DistributedTransactionIdentifierTraceState))
+	assert.Equal(t, int64(2), recorder.Attributes(span)[HTTPRequestContentLengthAttribute].AsInt64())
+}
+
+func TestNewHandler_TransactionHeader(t *testing.T) {
+	recorder := cgxtest.NewRecorder(t)
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /users/{id}", func(w http.ResponseWriter, r *http.Request) {})
+
+	response := httptest.NewRecorder()
+	NewHandler(mux, WithTracerProvider(recorder.TracerProvider())).ServeHTTP(response, httptest.NewRequest(http.MethodGet, "/users/42", nil))
+	assert.Empty(t, response.Header().Values(TransactionHeader))
+
+	response = httptest.NewRecorder()
+	NewHandler(mux, WithTracerProvider(recorder.TracerProvider()), WithTransactionHeader()).ServeHTTP(response, httptest.NewRequest(http.MethodGet, "/users/42", nil))
+	assert.Equal(t, "GET /users/{id}", response.Header().Get(TransactionHeader))
+}
+
+func TestResponseWriter_ResponseController(t *testing.T) {
+	recorder := cgxtest.NewRecorder(t)
+	handler := NewHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("chunk"))
+		assert.NoError(t, http.NewResponseController(w).Flush())
+	}), WithTracerProvider(recorder.TracerProvider()))
+
+	response := httptest.NewRecorder()
+	handler.ServeHTTP(response, httptest.NewRequest(http.MethodGet, "/", nil))
+	assert.True(t, response.Flushed)
+}
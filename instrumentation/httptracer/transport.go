@@ -0,0 +1,127 @@
+package httptracer
+
+import (
+	"io"
+	"net"
+	"net/http"
+	"sync"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	traceCore "go.opentelemetry.io/otel/trace"
+)
+
+type transport struct {
+	base   http.RoundTripper
+	config *config
+	tracer traceCore.Tracer
+}
+
+// NewTransport wraps base, http.DefaultTransport if nil, with a Client span per
+// request. The traceparent and tracestate headers are injected into requests,
+// so that the server joins the trace and the distributed transaction of the
+// caller, and the transaction the server started, returned in the
+// TransactionHeader, is recorded as cgx.transaction.downstream.
+//
+// The span ends when the response body is read to the end or closed.
+func NewTransport(base http.RoundTripper, options ...Option) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	c := newConfig(options)
+	return &transport{base: base, config: c, tracer: c.tracerProvider.Tracer(instrumentationName)}
+}
+
+func (t *transport) RoundTrip(r *http.Request) (*http.Response, error) {
+	ctx, span := t.tracer.Start(r.Context(), "HTTP "+r.Method,
+		traceCore.WithSpanKind(traceCore.SpanKindClient),
+		traceCore.WithAttributes(clientAttributes(r)...),
+		traceCore.WithAttributes(t.config.attributes...),
+	)
+	// a RoundTripper must not modify the request it is given
+	r = r.Clone(ctx)
+	t.config.propagator.Inject(ctx, propagation.HeaderCarrier(r.Header))
+
+	response, err := t.base.RoundTrip(r)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		span.End()
+		return response, err
+	}
+
+	span.SetAttributes(attribute.Int(HTTPStatusCodeAttribute, response.StatusCode))
+	if transaction := response.Header.Get(TransactionHeader); transaction != "" {
+		span.SetAttributes(attribute.String(DownstreamTransactionAttribute, transaction))
+	}
+	if response.StatusCode >= http.StatusBadRequest {
+		span.SetStatus(codes.Error, http.StatusText(response.StatusCode))
+	}
+	// switching protocols responses have a writable body that must not be hidden
+	if _, writable := response.Body.(io.Writer); response.Body == nil || response.Body == http.NoBody || writable {
+		span.End()
+		return response, nil
+	}
+	response.Body = &responseBody{ReadCloser: response.Body, span: span}
+	return response, nil
+}
+
+func clientAttributes(r *http.Request) []attribute.KeyValue {
+	// the query and the credentials of a URL may hold secrets
+	url := *r.URL
+	url.User = nil
+	url.RawQuery = ""
+	url.Fragment = ""
+	attributes := []attribute.KeyValue{
+		attribute.String(HTTPMethodAttribute, r.Method),
+		attribute.String(HTTPURLAttribute, url.String()),
+	}
+	host, port, err := net.SplitHostPort(r.URL.Host)
+	if err != nil {
+		host, port = r.URL.Host, ""
+	}
+	if host != "" {
+		attributes = append(attributes, attribute.String(NetPeerNameAttribute, host))
+	}
+	if port != "" {
+		attributes = append(attributes, attribute.String(NetPeerPortAttribute, port))
+	}
+	if r.ContentLength > 0 {
+		attributes = append(attributes, attribute.Int64(HTTPRequestContentLengthAttribute, r.ContentLength))
+	}
+	return attributes
+}
+
+// responseBody ends the span of a response once its body is read or closed,
+// recording its size.
+type responseBody struct {
+	io.ReadCloser
+	span traceCore.Span
+	read int64
+	once sync.Once
+}
+
+func (b *responseBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.read += int64(n)
+	if err == io.EOF {
+		b.end()
+	} else if err != nil {
+		b.span.RecordError(err)
+	}
+	return n, err
+}
+
+func (b *responseBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.end()
+	return err
+}
+
+func (b *responseBody) end() {
+	b.once.Do(func() {
+		b.span.SetAttributes(attribute.Int64(HTTPResponseContentLengthAttribute, b.read))
+		b.span.End()
+	})
+}
@@ -0,0 +1,109 @@
+package httptracer
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/codes"
+	traceCore "go.opentelemetry.io/otel/trace"
+
+	"github.com/coralogix/coralogix-opentelemetry-go/cgxtest"
+)
+
+func TestNewTransport_DistributedTransaction(t *testing.T) {
+	recorder := cgxtest.NewRecorder(t)
+
+	inventory := http.NewServeMux()
+	inventory.HandleFunc("GET /inventory/{sku}", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("3"))
+	})
+	downstream := httptest.NewServer(NewHandler(inventory, WithTracerProvider(recorder.TracerProvider()), WithTransactionHeader()))
+	defer downstream.Close()
+
+	client := &http.Client{Transport: NewTransport(nil, WithTracerProvider(recorder.TracerProvider()))}
+	orders := http.NewServeMux()
+	orders.HandleFunc("POST /orders", func(w http.ResponseWriter, r *http.Request) {
+		request, err := http.NewRequestWithContext(r.Context(), http.MethodGet, downstream.URL+"/inventory/A-1?token=secret", nil)
+		require.NoError(t, err)
+		response, err := client.Do(request)
+		require.NoError(t, err)
+		defer response.Body.Close()
+		_, _ = io.Copy(w, response.Body)
+	})
+	upstream := httptest.NewServer(NewHandler(orders, WithTracerProvider(recorder.TracerProvider())))
+	defer upstream.Close()
+
+	response, err := http.Post(upstream.URL+"/orders", "application/json", nil)
+	require.NoError(t, err)
+	body, err := io.ReadAll(response.Body)
+	require.NoError(t, err)
+	require.NoError(t, response.Body.Close())
+	assert.Equal(t, "3", string(body))
+	assert.Empty(t, response.Header.Get(TransactionHeader))
+
+	upstreamSpan := recorder.Span("POST /orders")
+	clientSpan := recorder.Span("HTTP GET")
+	downstreamSpan := recorder.Span("GET /inventory/{sku}")
+
+	assert.Equal(t, traceCore.SpanKindClient, clientSpan.SpanKind())
+	assert.Equal(t, upstreamSpan.SpanContext().SpanID(), clientSpan.Parent().SpanID())
+	assert.Equal(t, clientSpan.SpanContext().SpanID(), downstreamSpan.Parent().SpanID())
+	assert.True(t, downstreamSpan.Parent().IsRemote())
+	assert.Equal(t, upstreamSpan.SpanContext().TraceID(), downstreamSpan.SpanContext().TraceID())
+
+	recorder.AssertTransaction(clientSpan, "POST /orders").AssertDistributed(clientSpan, "POST /orders")
+	clientAttributes := recorder.Attributes(clientSpan)
+	assert.Equal(t, "GET /inventory/{sku}", clientAttributes[DownstreamTransactionAttribute].AsString())
+	assert.Equal(t, downstream.URL+"/inventory/A-1", clientAttributes[HTTPURLAttribute].AsString())
+	assert.Equal(t, int64(http.StatusOK), clientAttributes[HTTPStatusCodeAttribute].AsInt64())
+	assert.Equal(t, int64(1), clientAttributes[HTTPResponseContentLengthAttribute].AsInt64())
+	recorder.
+		AssertTransaction(downstreamSpan, "GET /inventory/{sku}").
+		AssertDistributed(downstreamSpan, "POST /orders").
+		AssertRoot(downstreamSpan)
+}
+
+func TestNewTransport_DoesNotModifyRequest(t *testing.T) {
+	recorder := cgxtest.NewRecorder(t)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.NotEmpty(t, r.Header.Get("traceparent"))
+	}))
+	defer server.Close()
+
+	ctx, parent := recorder.Tracer("test").Start(context.Background(), "parent")
+	defer parent.End()
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL, nil)
+	require.NoError(t, err)
+	response, err := NewTransport(nil, WithTracerProvider(recorder.TracerProvider())).RoundTrip(request)
+	require.NoError(t, err)
+	require.NoError(t, response.Body.Close())
+	assert.Empty(t, request.Header.Get("traceparent"))
+}
+
+func TestNewTransport_Errors(t *testing.T) {
+	recorder := cgxtest.NewRecorder(t)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	client := &http.Client{Transport: NewTransport(nil, WithTracerProvider(recorder.TracerProvider()))}
+
+	response, err := client.Get(server.URL)
+	require.NoError(t, err)
+	require.NoError(t, response.Body.Close())
+	span := recorder.Span("HTTP GET")
+	assert.Equal(t, codes.Error, span.Status().Code)
+	assert.Equal(t, int64(http.StatusServiceUnavailable), recorder.Attributes(span)[HTTPStatusCodeAttribute].AsInt64())
+
+	server.Close()
+	_, err = client.Post(server.URL, "text/plain", nil)
+	require.Error(t, err)
+	require.Len(t, recorder.Ended(), 2)
+	failed := recorder.Span("HTTP POST")
+	assert.Equal(t, codes.Error, failed.Status().Code)
+	assert.NotEmpty(t, failed.Events())
+}
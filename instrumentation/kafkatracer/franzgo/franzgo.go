@@ -0,0 +1,109 @@
+// Package franzgo traces twmb/franz-go producers with client hooks, and
+// consumers with the kafkatracer transaction propagation.
+package franzgo
+
+import (
+	"context"
+
+	"github.com/twmb/franz-go/pkg/kgo"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
+	traceCore "go.opentelemetry.io/otel/trace"
+
+	"github.com/coralogix/coralogix-opentelemetry-go/instrumentation/kafkatracer"
+)
+
+// HeaderCarrier adapts the headers of a record to a propagation.TextMapCarrier.
+type HeaderCarrier struct {
+	Record *kgo.Record
+}
+
+var _ propagation.TextMapCarrier = HeaderCarrier{}
+
+func (c HeaderCarrier) Get(key string) string {
+	for _, header := range c.Record.Headers {
+		if header.Key == key {
+			return string(header.Value)
+		}
+	}
+	return ""
+}
+
+func (c HeaderCarrier) Set(key string, value string) {
+	for i, header := range c.Record.Headers {
+		if header.Key == key {
+			c.Record.Headers[i].Value = []byte(value)
+			return
+		}
+	}
+	c.Record.Headers = append(c.Record.Headers, kgo.RecordHeader{Key: key, Value: []byte(value)})
+}
+
+func (c HeaderCarrier) Keys() []string {
+	keys := make([]string, 0, len(c.Record.Headers))
+	for _, header := range c.Record.Headers {
+		keys = append(keys, header.Key)
+	}
+	return keys
+}
+
+var (
+	_ kgo.HookProduceRecordBuffered   = (*Tracer)(nil)
+	_ kgo.HookProduceRecordUnbuffered = (*Tracer)(nil)
+)
+
+// Tracer starts a Producer span per record produced by the clients it is
+// hooked to with kgo.WithHooks, and Consumer spans for the records they poll.
+type Tracer struct {
+	tracer *kafkatracer.Tracer
+}
+
+func NewTracer(options ...kafkatracer.Option) *Tracer {
+	return &Tracer{tracer: kafkatracer.NewTracer(options...)}
+}
+
+// OnProduceRecordBuffered starts the Producer span of a record, a child of the
+// context it was produced with, and injects its trace context into the
+// headers of the record.
+func (t *Tracer) OnProduceRecordBuffered(record *kgo.Record) {
+	ctx := record.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	record.Context, _ = t.tracer.StartProducer(ctx, record.Topic, HeaderCarrier{Record: record},
+		attribute.Int(kafkatracer.MessagingPayloadSizeAttribute, len(record.Value)),
+	)
+}
+
+// OnProduceRecordUnbuffered ends the Producer span of a record once it is
+// acknowledged or failed.
+func (t *Tracer) OnProduceRecordUnbuffered(record *kgo.Record, err error) {
+	span := traceCore.SpanFromContext(record.Context)
+	if err == nil {
+		span.SetAttributes(
+			attribute.Int(kafkatracer.MessagingKafkaPartitionAttribute, int(record.Partition)),
+			attribute.Int64(kafkatracer.MessagingKafkaOffsetAttribute, record.Offset),
+		)
+	}
+	kafkatracer.EndSpan(span, err)
+}
+
+// StartConsumer starts the Consumer span of a polled record, which starts a
+// transaction in the distributed transaction of its producer. The span must be
+// ended with kafkatracer.EndSpan.
+func (t *Tracer) StartConsumer(ctx context.Context, record *kgo.Record) (context.Context, traceCore.Span) {
+	return t.tracer.StartConsumer(ctx, record.Topic, HeaderCarrier{Record: record},
+		attribute.Int(kafkatracer.MessagingKafkaPartitionAttribute, int(record.Partition)),
+		attribute.Int64(kafkatracer.MessagingKafkaOffsetAttribute, record.Offset),
+		attribute.Int(kafkatracer.MessagingPayloadSizeAttribute, len(record.Value)),
+	)
+}
+
+// Process calls handle with a polled record within its Consumer span, and
+// returns the error of handle.
+func (t *Tracer) Process(ctx context.Context, record *kgo.Record, handle func(ctx context.Context, record *kgo.Record) error) error {
+	ctx, span := t.StartConsumer(ctx, record)
+	err := handle(ctx, record)
+	kafkatracer.EndSpan(span, err)
+	return err
+}
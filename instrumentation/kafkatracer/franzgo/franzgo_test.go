@@ -0,0 +1,64 @@
+package franzgo
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/twmb/franz-go/pkg/kgo"
+	"go.opentelemetry.io/otel/codes"
+	traceCore "go.opentelemetry.io/otel/trace"
+
+	"github.com/coralogix/coralogix-opentelemetry-go/cgxtest"
+	"github.com/coralogix/coralogix-opentelemetry-go/instrumentation/kafkatracer"
+)
+
+func TestTracer(t *testing.T) {
+	recorder := cgxtest.NewRecorder(t)
+	tracer := NewTracer(kafkatracer.WithTracerProvider(recorder.TracerProvider()))
+
+	client, err := kgo.NewClient(
+		kgo.SeedBrokers("127.0.0.1:1"),
+		kgo.DefaultProduceTopic("orders"),
+		kgo.WithHooks(tracer),
+	)
+	require.NoError(t, err)
+	defer client.Close()
+
+	ctx, parent := recorder.Tracer("test").Start(context.Background(), "POST /orders", traceCore.WithSpanKind(traceCore.SpanKindServer))
+	record := &kgo.Record{Value: []byte("order-1")}
+	// no broker listens, so the record fails once its context is done
+	produceCtx, cancel := context.WithTimeout(ctx, 100*time.Millisecond)
+	defer cancel()
+	require.Error(t, client.ProduceSync(produceCtx, record).FirstErr())
+	parent.End()
+
+	producerSpan := recorder.Span("orders send")
+	assert.Equal(t, traceCore.SpanKindProducer, producerSpan.SpanKind())
+	assert.Equal(t, codes.Error, producerSpan.Status().Code)
+	recorder.AssertTransaction(producerSpan, "POST /orders")
+	assert.NotEmpty(t, HeaderCarrier{Record: record}.Get("traceparent"))
+
+	// consume the record as if it had been delivered
+	polled := &kgo.Record{Topic: "orders", Partition: 2, Offset: 7, Value: record.Value, Headers: record.Headers}
+	err = tracer.Process(context.Background(), polled, func(ctx context.Context, record *kgo.Record) error {
+		_, child := recorder.Tracer("test").Start(ctx, "save order")
+		child.End()
+		return nil
+	})
+	require.NoError(t, err)
+
+	require.Len(t, recorder.Ended(), 4)
+	child, consumerSpan := recorder.Span("save order"), recorder.Span("orders process")
+	assert.Equal(t, producerSpan.SpanContext().SpanID(), consumerSpan.Parent().SpanID())
+	require.Len(t, consumerSpan.Links(), 1)
+	recorder.
+		AssertTransaction(consumerSpan, "orders process").
+		AssertDistributed(consumerSpan, "POST /orders").
+		AssertTransaction(child, "orders process")
+	consumerAttributes := recorder.Attributes(consumerSpan)
+	assert.Equal(t, int64(2), consumerAttributes[kafkatracer.MessagingKafkaPartitionAttribute].AsInt64())
+	assert.Equal(t, int64(7), consumerAttributes[kafkatracer.MessagingKafkaOffsetAttribute].AsInt64())
+}
@@ -0,0 +1,142 @@
+// Package kafkago traces segmentio/kafka-go writers and readers with the
+// kafkatracer transaction propagation.
+package kafkago
+
+import (
+	"context"
+
+	"github.com/segmentio/kafka-go"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
+	traceCore "go.opentelemetry.io/otel/trace"
+
+	"github.com/coralogix/coralogix-opentelemetry-go/instrumentation/kafkatracer"
+)
+
+// HeaderCarrier adapts the headers of a message to a propagation.TextMapCarrier.
+type HeaderCarrier struct {
+	Message *kafka.Message
+}
+
+var _ propagation.TextMapCarrier = HeaderCarrier{}
+
+func (c HeaderCarrier) Get(key string) string {
+	for _, header := range c.Message.Headers {
+		if header.Key == key {
+			return string(header.Value)
+		}
+	}
+	return ""
+}
+
+func (c HeaderCarrier) Set(key string, value string) {
+	for i, header := range c.Message.Headers {
+		if header.Key == key {
+			c.Message.Headers[i].Value = []byte(value)
+			return
+		}
+	}
+	c.Message.Headers = append(c.Message.Headers, kafka.Header{Key: key, Value: []byte(value)})
+}
+
+func (c HeaderCarrier) Keys() []string {
+	keys := make([]string, 0, len(c.Message.Headers))
+	for _, header := range c.Message.Headers {
+		keys = append(keys, header.Key)
+	}
+	return keys
+}
+
+// MessageWriter writes messages, like *kafka.Writer.
+type MessageWriter interface {
+	WriteMessages(ctx context.Context, messages ...kafka.Message) error
+}
+
+// Writer starts a Producer span per message it writes.
+type Writer struct {
+	writer MessageWriter
+	// topic is the topic of the wrapped *kafka.Writer, if any.
+	topic  string
+	tracer *kafkatracer.Tracer
+}
+
+func NewWriter(writer MessageWriter, options ...kafkatracer.Option) *Writer {
+	w := &Writer{writer: writer, tracer: kafkatracer.NewTracer(options...)}
+	if kafkaWriter, ok := writer.(*kafka.Writer); ok {
+		w.topic = kafkaWriter.Topic
+	}
+	return w
+}
+
+// WriteMessages writes messages with the trace context of their producer span
+// in their headers. The spans end once the messages are written.
+func (w *Writer) WriteMessages(ctx context.Context, messages ...kafka.Message) error {
+	// the messages of the caller must not be modified
+	messages = append([]kafka.Message(nil), messages...)
+	spans := make([]traceCore.Span, len(messages))
+	for i := range messages {
+		message := &messages[i]
+		message.Headers = append([]kafka.Header(nil), message.Headers...)
+		topic := message.Topic
+		if topic == "" {
+			topic = w.topic
+		}
+		_, spans[i] = w.tracer.StartProducer(ctx, topic, HeaderCarrier{Message: message},
+			attribute.Int(kafkatracer.MessagingPayloadSizeAttribute, len(message.Value)),
+		)
+	}
+	err := w.writer.WriteMessages(ctx, messages...)
+	for _, span := range spans {
+		kafkatracer.EndSpan(span, err)
+	}
+	return err
+}
+
+// MessageReader reads messages, like *kafka.Reader.
+type MessageReader interface {
+	ReadMessage(ctx context.Context) (kafka.Message, error)
+}
+
+// Reader processes the messages it reads in Consumer spans.
+type Reader struct {
+	reader MessageReader
+	// groupID is the consumer group of the wrapped *kafka.Reader, if any.
+	groupID string
+	tracer  *kafkatracer.Tracer
+}
+
+func NewReader(reader MessageReader, options ...kafkatracer.Option) *Reader {
+	r := &Reader{reader: reader, tracer: kafkatracer.NewTracer(options...)}
+	if kafkaReader, ok := reader.(*kafka.Reader); ok {
+		r.groupID = kafkaReader.Config().GroupID
+	}
+	return r
+}
+
+// Consume reads a message and calls handle with it within a Consumer span,
+// which starts a transaction in the distributed transaction of its producer.
+// It returns the error of the read or of handle.
+func (r *Reader) Consume(ctx context.Context, handle func(ctx context.Context, message kafka.Message) error) error {
+	message, err := r.reader.ReadMessage(ctx)
+	if err != nil {
+		return err
+	}
+	ctx, span := r.StartConsumer(ctx, message)
+	err = handle(ctx, message)
+	kafkatracer.EndSpan(span, err)
+	return err
+}
+
+// StartConsumer starts the Consumer span of a message, for messages read with
+// FetchMessage. The span must be ended with kafkatracer.EndSpan.
+func (r *Reader) StartConsumer(ctx context.Context, message kafka.Message) (context.Context, traceCore.Span) {
+	attributes := []attribute.KeyValue{
+		attribute.Int(kafkatracer.MessagingKafkaPartitionAttribute, message.Partition),
+		attribute.Int64(kafkatracer.MessagingKafkaOffsetAttribute, message.Offset),
+		attribute.Int(kafkatracer.MessagingPayloadSizeAttribute, len(message.Value)),
+	}
+	if r.groupID != "" {
+		attributes = append(attributes, attribute.String(kafkatracer.MessagingKafkaConsumerGroupAttribute, r.groupID))
+	}
+	return r.tracer.StartConsumer(ctx, message.Topic, HeaderCarrier{Message: &message}, attributes...)
+}
@@ -0,0 +1,96 @@
+package kafkago
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/segmentio/kafka-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/codes"
+	traceCore "go.opentelemetry.io/otel/trace"
+
+	"github.com/coralogix/coralogix-opentelemetry-go/cgxtest"
+	"github.com/coralogix/coralogix-opentelemetry-go/instrumentation/kafkatracer"
+)
+
+// topic is an in-memory topic, written and read like a kafka-go writer and reader.
+type topic struct {
+	messages []kafka.Message
+	err      error
+}
+
+func (t *topic) WriteMessages(_ context.Context, messages ...kafka.Message) error {
+	if t.err != nil {
+		return t.err
+	}
+	t.messages = append(t.messages, messages...)
+	return nil
+}
+
+func (t *topic) ReadMessage(_ context.Context) (kafka.Message, error) {
+	if len(t.messages) == 0 {
+		return kafka.Message{}, errors.New("no message")
+	}
+	message := t.messages[0]
+	t.messages = t.messages[1:]
+	message.Offset = 42
+	return message, nil
+}
+
+func TestWriterAndReader(t *testing.T) {
+	recorder := cgxtest.NewRecorder(t)
+	orders := &topic{}
+	writer := NewWriter(orders, kafkatracer.WithTracerProvider(recorder.TracerProvider()))
+	reader := NewReader(orders, kafkatracer.WithTracerProvider(recorder.TracerProvider()))
+
+	ctx, parent := recorder.Tracer("test").Start(context.Background(), "POST /orders", traceCore.WithSpanKind(traceCore.SpanKindServer))
+	messages := []kafka.Message{{Topic: "orders", Value: []byte("order-1"), Headers: []kafka.Header{{Key: "source", Value: []byte("web")}}}}
+	require.NoError(t, writer.WriteMessages(ctx, messages...))
+	parent.End()
+	assert.Len(t, messages[0].Headers, 1, "the messages of the caller are not modified")
+
+	var handled context.Context
+	require.NoError(t, reader.Consume(context.Background(), func(ctx context.Context, message kafka.Message) error {
+		handled = ctx
+		assert.Equal(t, "order-1", string(message.Value))
+		return nil
+	}))
+
+	require.Len(t, recorder.Ended(), 3)
+	producerSpan, consumerSpan := recorder.Span("orders send"), recorder.Span("orders process")
+	assert.Equal(t, int64(len("order-1")), recorder.Attributes(producerSpan)[kafkatracer.MessagingPayloadSizeAttribute].AsInt64())
+
+	assert.Equal(t, consumerSpan.SpanContext(), traceCore.SpanContextFromContext(handled))
+	assert.Equal(t, producerSpan.SpanContext().SpanID(), consumerSpan.Parent().SpanID())
+	require.Len(t, consumerSpan.Links(), 1)
+	recorder.AssertTransaction(consumerSpan, "orders process").AssertDistributed(consumerSpan, "POST /orders")
+	assert.Equal(t, int64(42), recorder.Attributes(consumerSpan)[kafkatracer.MessagingKafkaOffsetAttribute].AsInt64())
+}
+
+func TestWriter_Error(t *testing.T) {
+	recorder := cgxtest.NewRecorder(t)
+	writer := NewWriter(&topic{err: errors.New("leader not available")}, kafkatracer.WithTracerProvider(recorder.TracerProvider()))
+
+	err := writer.WriteMessages(context.Background(), kafka.Message{Topic: "a"}, kafka.Message{Topic: "b"})
+	require.Error(t, err)
+
+	require.Len(t, recorder.Ended(), 2)
+	assert.Equal(t, codes.Error, recorder.Span("a send").Status().Code)
+	assert.Equal(t, codes.Error, recorder.Span("b send").Status().Code)
+}
+
+func TestNewWriter_KafkaWriterTopic(t *testing.T) {
+	recorder := cgxtest.NewRecorder(t)
+	kafkaWriter := &kafka.Writer{Addr: kafka.TCP("127.0.0.1:1"), Topic: "payments"}
+	defer kafkaWriter.Close()
+	writer := NewWriter(kafkaWriter, kafkatracer.WithTracerProvider(recorder.TracerProvider()))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_ = writer.WriteMessages(ctx, kafka.Message{Value: []byte("x")})
+
+	require.Len(t, recorder.Ended(), 1)
+	assert.NotNil(t, recorder.Span("payments send"))
+}
@@ -0,0 +1,66 @@
+// Package kafkatracer traces Kafka producers and consumers. The trace context,
+// cgx tracestate included, travels in the message headers: producer spans
+// belong to the transaction of the code that produced the message, and
+// consumer spans, which the CoralogixSampler treats as transaction starts,
+// continue its distributed transaction across the topic.
+//
+// The tracer is a messaging.Tracer for the kafka system. The kafkago and
+// franzgo packages adapt it to segmentio/kafka-go and twmb/franz-go.
+package kafkatracer
+
+import (
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
+	traceCore "go.opentelemetry.io/otel/trace"
+
+	"github.com/coralogix/coralogix-opentelemetry-go/instrumentation/messaging"
+)
+
+const (
+	MessagingSystemAttribute             = messaging.MessagingSystemAttribute
+	MessagingDestinationAttribute        = messaging.MessagingDestinationAttribute
+	MessagingDestinationKindAttribute    = "messaging.destination_kind"
+	MessagingOperationAttribute          = messaging.MessagingOperationAttribute
+	MessagingPayloadSizeAttribute        = "messaging.message_payload_size_bytes"
+	MessagingKafkaPartitionAttribute     = "messaging.kafka.partition"
+	MessagingKafkaOffsetAttribute        = "messaging.kafka.message.offset"
+	MessagingKafkaConsumerGroupAttribute = "messaging.kafka.consumer_group"
+)
+
+// Option configures the tracer; the options are those of messaging.
+type Option = messaging.Option
+
+// WithTracerProvider sets the provider of the producer and consumer spans,
+// otel.GetTracerProvider() by default.
+func WithTracerProvider(provider traceCore.TracerProvider) Option {
+	return messaging.WithTracerProvider(provider)
+}
+
+// WithPropagator sets the propagator writing the trace context into the
+// message headers and reading it back, W3C trace context and baggage by
+// default.
+func WithPropagator(propagator propagation.TextMapPropagator) Option {
+	return messaging.WithPropagator(propagator)
+}
+
+// WithAttributes adds attributes, e.g. the bootstrap servers, to the span of
+// every message.
+func WithAttributes(attributes ...attribute.KeyValue) Option {
+	return messaging.WithAttributes(attributes...)
+}
+
+// EndSpan ends a producer or consumer span, recording err if any.
+func EndSpan(span traceCore.Span, err error) {
+	messaging.EndSpan(span, err)
+}
+
+// Tracer starts the producer and consumer spans of the messages of Kafka
+// topics, recording their destination kind as topic.
+type Tracer struct {
+	*messaging.Tracer
+}
+
+func NewTracer(options ...Option) *Tracer {
+	options = append([]Option{messaging.WithAttributes(attribute.String(MessagingDestinationKindAttribute, "topic"))}, options...)
+	return &Tracer{Tracer: messaging.NewTracer("kafka", options...)}
+}
@@ -0,0 +1,69 @@
+package kafkatracer
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	traceCore "go.opentelemetry.io/otel/trace"
+
+	"github.com/coralogix/coralogix-opentelemetry-go/cgxtest"
+)
+
+func TestTracer_PropagatesTransactionAcrossTopic(t *testing.T) {
+	recorder := cgxtest.NewRecorder(t)
+	tracer := NewTracer(WithTracerProvider(recorder.TracerProvider()))
+
+	ctx, parent := recorder.Tracer("test").Start(context.Background(), "POST /orders", traceCore.WithSpanKind(traceCore.SpanKindServer))
+	headers := propagation.MapCarrier{}
+	_, producer := tracer.StartProducer(ctx, "orders", headers)
+	EndSpan(producer, nil)
+	parent.End()
+	require.Contains(t, headers, "traceparent")
+	require.Contains(t, headers["tracestate"], "cgx_transaction_distributed=POST /orders")
+
+	_, consumer := tracer.StartConsumer(context.Background(), "orders", headers, attribute.Int(MessagingKafkaPartitionAttribute, 3))
+	EndSpan(consumer, errors.New("poison message"))
+
+	require.Len(t, recorder.Ended(), 3)
+	producerSpan, consumerSpan := recorder.Span("orders send"), recorder.Span("orders process")
+
+	assert.Equal(t, traceCore.SpanKindProducer, producerSpan.SpanKind())
+	recorder.AssertTransaction(producerSpan, "POST /orders")
+	producerAttributes := recorder.Attributes(producerSpan)
+	assert.Equal(t, "kafka", producerAttributes[MessagingSystemAttribute].AsString())
+	assert.Equal(t, "orders", producerAttributes[MessagingDestinationAttribute].AsString())
+	assert.Equal(t, "topic", producerAttributes[MessagingDestinationKindAttribute].AsString())
+
+	assert.Equal(t, traceCore.SpanKindConsumer, consumerSpan.SpanKind())
+	assert.Equal(t, producerSpan.SpanContext().SpanID(), consumerSpan.Parent().SpanID())
+	require.Len(t, consumerSpan.Links(), 1)
+	assert.Equal(t, producerSpan.SpanContext().SpanID(), consumerSpan.Links()[0].SpanContext.SpanID())
+	recorder.
+		AssertTransaction(consumerSpan, "orders process").
+		AssertDistributed(consumerSpan, "POST /orders").
+		AssertRoot(consumerSpan)
+	consumerAttributes := recorder.Attributes(consumerSpan)
+	assert.Equal(t, "process", consumerAttributes[MessagingOperationAttribute].AsString())
+	assert.Equal(t, int64(3), consumerAttributes[MessagingKafkaPartitionAttribute].AsInt64())
+	assert.Equal(t, codes.Error, consumerSpan.Status().Code)
+}
+
+func TestTracer_ConsumerWithoutTraceContext(t *testing.T) {
+	recorder := cgxtest.NewRecorder(t)
+	tracer := NewTracer(WithTracerProvider(recorder.TracerProvider()))
+
+	ctx, poll := recorder.Tracer("test").Start(context.Background(), "poll")
+	_, consumer := tracer.StartConsumer(ctx, "orders", propagation.MapCarrier{})
+	EndSpan(consumer, nil)
+	poll.End()
+
+	consumerSpan := recorder.Span("orders process")
+	assert.Equal(t, poll.SpanContext().SpanID(), consumerSpan.Parent().SpanID())
+	assert.Empty(t, consumerSpan.Links())
+}
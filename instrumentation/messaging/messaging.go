@@ -0,0 +1,263 @@
+// Package messaging traces message producers and consumers of any broker,
+// e.g. SQS, NATS or RabbitMQ, whose message headers can be accessed through a
+// propagation.TextMapCarrier. The trace context, cgx tracestate included,
+// travels in the headers, so that distributed transactions stay intact across
+// queues. See kafkatracer for Kafka clients.
+package messaging
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	traceCore "go.opentelemetry.io/otel/trace"
+
+	"github.com/coralogix/coralogix-opentelemetry-go/sampler"
+)
+
+const (
+	instrumentationName = "github.com/coralogix/coralogix-opentelemetry-go/instrumentation/messaging"
+
+	MessagingSystemAttribute            = "messaging.system"
+	MessagingDestinationAttribute       = "messaging.destination"
+	MessagingOperationAttribute         = "messaging.operation"
+	MessagingBatchMessageCountAttribute = "messaging.batch.message_count"
+)
+
+// HeaderCarrier adapts string-map message headers to a
+// propagation.TextMapCarrier. Unlike propagation.MapCarrier, it finds keys
+// whatever their case, since some brokers and clients change it.
+type HeaderCarrier map[string]string
+
+var _ propagation.TextMapCarrier = HeaderCarrier{}
+
+func (c HeaderCarrier) Get(key string) string {
+	if value, ok := c[key]; ok {
+		return value
+	}
+	for k, value := range c {
+		if strings.EqualFold(k, key) {
+			return value
+		}
+	}
+	return ""
+}
+
+func (c HeaderCarrier) Set(key string, value string) {
+	c[key] = value
+}
+
+func (c HeaderCarrier) Keys() []string {
+	keys := make([]string, 0, len(c))
+	for key := range c {
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+type Option func(*Tracer)
+
+// WithTracerProvider sets the provider of the producer, consumer and batch
+// spans, otel.GetTracerProvider() by default.
+func WithTracerProvider(provider traceCore.TracerProvider) Option {
+	return func(t *Tracer) {
+		t.tracerProvider = provider
+	}
+}
+
+// WithPropagator sets the propagator writing the trace context into message
+// headers and reading it back, W3C trace context and baggage by default.
+// Consumers only join the distributed transaction of a producer through a
+// propagator carrying the tracestate.
+func WithPropagator(propagator propagation.TextMapPropagator) Option {
+	return func(t *Tracer) {
+		t.propagator = propagator
+	}
+}
+
+// WithAttributes adds attributes, e.g. the broker address, to the span of
+// every message and batch.
+func WithAttributes(attributes ...attribute.KeyValue) Option {
+	return func(t *Tracer) {
+		t.attributes = append(t.attributes, attributes...)
+	}
+}
+
+// Tracer starts the producer and consumer spans of the messages of a
+// messaging system.
+type Tracer struct {
+	system         string
+	tracerProvider traceCore.TracerProvider
+	tracer         traceCore.Tracer
+	propagator     propagation.TextMapPropagator
+	attributes     []attribute.KeyValue
+}
+
+// NewTracer returns a tracer for the messaging system, e.g. aws_sqs, nats or
+// rabbitmq, recorded as messaging.system.
+func NewTracer(system string, options ...Option) *Tracer {
+	t := &Tracer{system: system}
+	for _, option := range options {
+		option(t)
+	}
+	if t.tracerProvider == nil {
+		t.tracerProvider = otel.GetTracerProvider()
+	}
+	if t.propagator == nil {
+		t.propagator = propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{})
+	}
+	t.tracer = t.tracerProvider.Tracer(instrumentationName)
+	return t
+}
+
+// StartProducer starts a Producer span, named "<destination> send", for a
+// message and injects its trace context into the headers of the message.
+func (t *Tracer) StartProducer(ctx context.Context, destination string, headers propagation.TextMapCarrier, attributes ...attribute.KeyValue) (context.Context, traceCore.Span) {
+	ctx, span := t.tracer.Start(ctx, destination+" send",
+		traceCore.WithSpanKind(traceCore.SpanKindProducer),
+		traceCore.WithAttributes(t.messageAttributes(destination, "")...),
+		traceCore.WithAttributes(attributes...),
+	)
+	t.propagator.Inject(ctx, headers)
+	return ctx, span
+}
+
+// StartConsumer starts a Consumer span, named "<destination> process", for
+// the processing of a message. The span is a child of the producer span found
+// in the headers of the message, and is linked to it, so that the transaction
+// it starts belongs to the distributed transaction of the producer. Messages
+// without trace context are processed in a child of ctx.
+func (t *Tracer) StartConsumer(ctx context.Context, destination string, headers propagation.TextMapCarrier, attributes ...attribute.KeyValue) (context.Context, traceCore.Span) {
+	options := []traceCore.SpanStartOption{
+		traceCore.WithSpanKind(traceCore.SpanKindConsumer),
+		traceCore.WithAttributes(t.messageAttributes(destination, "process")...),
+		traceCore.WithAttributes(attributes...),
+	}
+	if producerCtx, producer := t.extract(ctx, headers); producer.IsValid() {
+		ctx = producerCtx
+		options = append(options, traceCore.WithLinks(traceCore.Link{SpanContext: producer}))
+	}
+	return t.tracer.Start(ctx, destination+" process", options...)
+}
+
+// Message is a message of a batch.
+type Message struct {
+	// Destination is the queue, topic or subject the message was received from.
+	Destination string
+	// Headers holds the trace context of the producer of the message, if any.
+	Headers propagation.TextMapCarrier
+	// Attributes are added to the span processing the message, e.g. its id.
+	Attributes []attribute.KeyValue
+}
+
+// ProcessBatch processes a batch of messages received together, calling
+// process with the index of each message in turn.
+//
+// The batch is processed in a Consumer span, named "<destination> receive"
+// after the destination of its first message, which is linked to the producer
+// span of every message. Each message is processed in a child span, named
+// "<destination> process", which starts a transaction named after its
+// destination, like sampler.StartNewTransaction: spans started from the
+// context process is given belong to the transaction of the destination of
+// the message rather than to the one of the batch, and to the distributed
+// transaction of the producer of the message, if any, rather than to the one
+// of the batch.
+//
+// Every message is processed even when some fail; ProcessBatch returns their
+// errors joined.
+func (t *Tracer) ProcessBatch(ctx context.Context, messages []Message, process func(ctx context.Context, i int) error) error {
+	if len(messages) == 0 {
+		return nil
+	}
+	links := make([]traceCore.Link, 0, len(messages))
+	producers := make([]traceCore.SpanContext, len(messages))
+	for i, message := range messages {
+		_, producers[i] = t.extract(ctx, message.Headers)
+		if producers[i].IsValid() {
+			links = append(links, traceCore.Link{SpanContext: producers[i]})
+		}
+	}
+	destination := messages[0].Destination
+	batchCtx, batch := t.tracer.Start(ctx, destination+" receive",
+		traceCore.WithSpanKind(traceCore.SpanKindConsumer),
+		traceCore.WithAttributes(t.messageAttributes(destination, "receive")...),
+		traceCore.WithAttributes(attribute.Int(MessagingBatchMessageCountAttribute, len(messages))),
+		traceCore.WithLinks(links...),
+	)
+
+	var errs []error
+	for i, message := range messages {
+		options := []traceCore.SpanStartOption{
+			traceCore.WithAttributes(t.messageAttributes(message.Destination, "process")...),
+			traceCore.WithAttributes(message.Attributes...),
+		}
+		if producers[i].IsValid() {
+			options = append(options, traceCore.WithLinks(traceCore.Link{SpanContext: producers[i]}))
+		}
+		messageCtx, span := t.tracer.Start(withDistributedTransaction(batchCtx, producers[i]), message.Destination+" process", options...)
+		sampler.StartNewTransaction(span, message.Destination)
+		err := process(messageCtx, i)
+		EndSpan(span, err)
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	err := errors.Join(errs...)
+	EndSpan(batch, err)
+	return err
+}
+
+// extract returns ctx with the trace context found in the headers of a
+// message, and the span context of its producer, which is invalid if the
+// headers hold none.
+func (t *Tracer) extract(ctx context.Context, headers propagation.TextMapCarrier) (context.Context, traceCore.SpanContext) {
+	if headers == nil {
+		return ctx, traceCore.SpanContext{}
+	}
+	// extract into a context without span, so that the span of ctx is not mistaken for the producer
+	ctx = t.propagator.Extract(traceCore.ContextWithSpanContext(ctx, traceCore.SpanContext{}), headers)
+	return ctx, traceCore.SpanContextFromContext(ctx)
+}
+
+// withDistributedTransaction returns ctx whose span context carries the
+// distributed transaction of producer in its tracestate, so that the spans
+// started from it continue the distributed transaction of the producer while
+// remaining children of the span of ctx.
+func withDistributedTransaction(ctx context.Context, producer traceCore.SpanContext) context.Context {
+	distributed := producer.TraceState().Get(sampler.DistributedTransactionIdentifierTraceState)
+	if distributed == "" {
+		return ctx
+	}
+	parent := traceCore.SpanContextFromContext(ctx)
+	traceState, err := parent.TraceState().Insert(sampler.DistributedTransactionIdentifierTraceState, distributed)
+	if err != nil {
+		return ctx
+	}
+	return traceCore.ContextWithSpanContext(ctx, parent.WithTraceState(traceState))
+}
+
+// messageAttributes returns the attributes of a message of destination;
+// sending has no messaging.operation.
+func (t *Tracer) messageAttributes(destination string, operation string) []attribute.KeyValue {
+	attributes := []attribute.KeyValue{
+		attribute.String(MessagingSystemAttribute, t.system),
+		attribute.String(MessagingDestinationAttribute, destination),
+	}
+	if operation != "" {
+		attributes = append(attributes, attribute.String(MessagingOperationAttribute, operation))
+	}
+	return append(attributes, t.attributes...)
+}
+
+// EndSpan ends a producer or consumer span, recording err if any.
+func EndSpan(span traceCore.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
@@ -0,0 +1,106 @@
+package messaging
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	traceSdk "go.opentelemetry.io/otel/sdk/trace"
+	traceCore "go.opentelemetry.io/otel/trace"
+
+	"github.com/coralogix/coralogix-opentelemetry-go/cgxtest"
+)
+
+// produce sends a message to destination from a server span named transaction,
+// and returns its headers.
+func produce(recorder *cgxtest.Recorder, tracer *Tracer, transaction string, destination string) HeaderCarrier {
+	ctx, server := recorder.Tracer("test").Start(context.Background(), transaction, traceCore.WithSpanKind(traceCore.SpanKindServer))
+	defer server.End()
+	headers := HeaderCarrier{}
+	_, producer := tracer.StartProducer(ctx, destination, headers)
+	EndSpan(producer, nil)
+	return headers
+}
+
+func TestHeaderCarrier(t *testing.T) {
+	headers := HeaderCarrier{"Traceparent": "00-5bd66ef5095369c7b0d1f8f4bd33716a-c532cb4098ac3dd2-01"}
+	assert.Equal(t, "00-5bd66ef5095369c7b0d1f8f4bd33716a-c532cb4098ac3dd2-01", headers.Get("traceparent"))
+	assert.Equal(t, "", headers.Get("tracestate"))
+	headers.Set("tracestate", "cgx_transaction=a")
+	assert.ElementsMatch(t, []string{"Traceparent", "tracestate"}, headers.Keys())
+}
+
+func TestTracer_StartConsumer(t *testing.T) {
+	recorder := cgxtest.NewRecorder(t)
+	tracer := NewTracer("aws_sqs", WithTracerProvider(recorder.TracerProvider()))
+	headers := produce(recorder, tracer, "POST /orders", "orders")
+
+	_, consumer := tracer.StartConsumer(context.Background(), "orders", headers)
+	EndSpan(consumer, nil)
+
+	producerSpan := recorder.Span("orders send")
+	consumerSpan := recorder.Span("orders process")
+	assert.Equal(t, traceCore.SpanKindConsumer, consumerSpan.SpanKind())
+	assert.Equal(t, producerSpan.SpanContext().SpanID(), consumerSpan.Parent().SpanID())
+	require.Len(t, consumerSpan.Links(), 1)
+	assert.Equal(t, "aws_sqs", recorder.Attributes(consumerSpan)[MessagingSystemAttribute].AsString())
+	recorder.AssertTransaction(consumerSpan, "orders process").AssertDistributed(consumerSpan, "POST /orders")
+}
+
+func TestTracer_ProcessBatch(t *testing.T) {
+	recorder := cgxtest.NewRecorder(t)
+	tracer := NewTracer("aws_sqs", WithTracerProvider(recorder.TracerProvider()))
+	messages := []Message{
+		{Destination: "orders", Headers: produce(recorder, tracer, "POST /orders", "orders"), Attributes: []attribute.KeyValue{attribute.String("messaging.message_id", "m1")}},
+		{Destination: "refunds", Headers: produce(recorder, tracer, "POST /refunds", "refunds")},
+		{Destination: "orders", Headers: HeaderCarrier{}},
+	}
+
+	err := tracer.ProcessBatch(context.Background(), messages, func(ctx context.Context, i int) error {
+		_, child := recorder.Tracer("test").Start(ctx, fmt.Sprintf("handle %d", i))
+		child.End()
+		if messages[i].Destination == "refunds" {
+			return errors.New("refund rejected")
+		}
+		return nil
+	})
+	require.EqualError(t, err, "refund rejected")
+
+	batch := recorder.Span("orders receive")
+	assert.Equal(t, traceCore.SpanKindConsumer, batch.SpanKind())
+	assert.False(t, batch.Parent().IsValid())
+	assert.Len(t, batch.Links(), 2)
+	assert.Equal(t, codes.Error, batch.Status().Code)
+	assert.Equal(t, int64(3), recorder.Attributes(batch)[MessagingBatchMessageCountAttribute].AsInt64())
+	recorder.AssertTransaction(batch, "orders receive")
+
+	var processed []traceSdk.ReadOnlySpan
+	for _, span := range recorder.Ended() {
+		if span.Parent().SpanID() == batch.SpanContext().SpanID() {
+			processed = append(processed, span)
+		}
+	}
+	require.Len(t, processed, 3)
+	for i, span := range processed {
+		assert.Equal(t, messages[i].Destination+" process", span.Name())
+		recorder.AssertTransaction(span, messages[i].Destination).AssertRoot(span)
+	}
+	assert.Equal(t, "m1", recorder.Attributes(processed[0])["messaging.message_id"].AsString())
+	assert.Equal(t, recorder.Span("orders send").SpanContext().SpanID(), processed[0].Links()[0].SpanContext.SpanID())
+	assert.Equal(t, codes.Error, processed[1].Status().Code)
+	assert.Empty(t, processed[2].Links())
+	recorder.
+		AssertDistributed(processed[0], "POST /orders").
+		AssertDistributed(processed[1], "POST /refunds").
+		AssertDistributed(processed[2], "orders receive")
+
+	recorder.
+		AssertTransaction(recorder.Span("handle 0"), "orders").AssertDistributed(recorder.Span("handle 0"), "POST /orders").
+		AssertTransaction(recorder.Span("handle 1"), "refunds").AssertDistributed(recorder.Span("handle 1"), "POST /refunds").
+		AssertTransaction(recorder.Span("handle 2"), "orders").AssertDistributed(recorder.Span("handle 2"), "orders receive")
+}
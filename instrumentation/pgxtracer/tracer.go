@@ -0,0 +1,196 @@
+// Package pgxtracer traces jackc/pgx queries, batches and COPY FROM calls with
+// normalized statements. Spans are Client spans, so with the CoralogixSampler
+// they belong to the transaction of the span that issued the query.
+package pgxtracer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	traceCore "go.opentelemetry.io/otel/trace"
+
+	sqlparser "github.com/coralogix/coralogix-opentelemetry-go/processor/sql"
+)
+
+const (
+	instrumentationName = "github.com/coralogix/coralogix-opentelemetry-go/instrumentation/pgxtracer"
+
+	querySpanName    = "pgx.query"
+	batchSpanName    = "pgx.batch"
+	copyFromSpanName = "pgx.copy_from"
+
+	BatchSizeAttribute  = "db.operation.batch.size"
+	StatusCodeAttribute = "db.response.status_code"
+
+	exceptionEventName     = "exception"
+	exceptionTypeAttribute = "exception.type"
+)
+
+var (
+	_ pgx.QueryTracer    = (*Tracer)(nil)
+	_ pgx.BatchTracer    = (*Tracer)(nil)
+	_ pgx.CopyFromTracer = (*Tracer)(nil)
+)
+
+// Tracer implements pgx.QueryTracer, pgx.BatchTracer and pgx.CopyFromTracer.
+// Set it as the Tracer of a pgx.ConnConfig.
+type Tracer struct {
+	tracerProvider traceCore.TracerProvider
+	tracer         traceCore.Tracer
+	attributes     []attribute.KeyValue
+	policy         sqlparser.RedactionPolicy
+}
+
+type Option func(*Tracer)
+
+// WithTracerProvider sets the provider of the query, batch and COPY FROM spans,
+// otel.GetTracerProvider() by default.
+func WithTracerProvider(provider traceCore.TracerProvider) Option {
+	return func(t *Tracer) {
+		t.tracerProvider = provider
+	}
+}
+
+// WithAttributes adds attributes to every span, next to those of the connection.
+func WithAttributes(attributes ...attribute.KeyValue) Option {
+	return func(t *Tracer) {
+		t.attributes = append(t.attributes, attributes...)
+	}
+}
+
+// WithRedactionPolicy keeps, in span names and db.statement, the literals of
+// the columns policy allows; pgx statements are otherwise fully redacted.
+func WithRedactionPolicy(policy sqlparser.RedactionPolicy) Option {
+	return func(t *Tracer) {
+		t.policy = policy
+	}
+}
+
+func NewTracer(options ...Option) *Tracer {
+	t := &Tracer{}
+	for _, option := range options {
+		option(t)
+	}
+	if t.tracerProvider == nil {
+		t.tracerProvider = otel.GetTracerProvider()
+	}
+	t.tracer = t.tracerProvider.Tracer(instrumentationName)
+	return t
+}
+
+func (t *Tracer) TraceQueryStart(ctx context.Context, conn *pgx.Conn, data pgx.TraceQueryStartData) context.Context {
+	name, attributes := t.statementAttributes(ctx, querySpanName, data.SQL)
+	ctx, _ = t.start(ctx, conn, name, attributes...)
+	return ctx
+}
+
+func (t *Tracer) TraceQueryEnd(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryEndData) {
+	endSpan(traceCore.SpanFromContext(ctx), data.CommandTag, data.Err)
+}
+
+func (t *Tracer) TraceBatchStart(ctx context.Context, conn *pgx.Conn, data pgx.TraceBatchStartData) context.Context {
+	size := 0
+	if data.Batch != nil {
+		size = data.Batch.Len()
+	}
+	ctx, _ = t.start(ctx, conn, batchSpanName,
+		attribute.String(sqlparser.DBOperationAttribute, "BATCH"),
+		attribute.Int(BatchSizeAttribute, size),
+	)
+	return ctx
+}
+
+// TraceBatchQuery is called once a batched query has completed, so its span
+// only marks the point in the batch where the result was read.
+func (t *Tracer) TraceBatchQuery(ctx context.Context, conn *pgx.Conn, data pgx.TraceBatchQueryData) {
+	name, attributes := t.statementAttributes(ctx, querySpanName, data.SQL)
+	_, span := t.start(ctx, conn, name, attributes...)
+	endSpan(span, data.CommandTag, data.Err)
+}
+
+func (t *Tracer) TraceBatchEnd(ctx context.Context, _ *pgx.Conn, data pgx.TraceBatchEndData) {
+	endSpan(traceCore.SpanFromContext(ctx), pgconn.CommandTag{}, data.Err)
+}
+
+func (t *Tracer) TraceCopyFromStart(ctx context.Context, conn *pgx.Conn, data pgx.TraceCopyFromStartData) context.Context {
+	name := copyFromSpanName
+	attributes := []attribute.KeyValue{attribute.String(sqlparser.DBOperationAttribute, "COPY")}
+	if len(data.TableName) > 0 {
+		table := strings.Join(data.TableName, ".")
+		name = fmt.Sprintf("COPY %s(%s) FROM STDIN", table, strings.Join(data.ColumnNames, ", "))
+		attributes = append(attributes,
+			attribute.String(sqlparser.DBStatementAttribute, name),
+			attribute.String(sqlparser.DBTableAttribute, table),
+		)
+	}
+	ctx, _ = t.start(ctx, conn, name, attributes...)
+	return ctx
+}
+
+func (t *Tracer) TraceCopyFromEnd(ctx context.Context, _ *pgx.Conn, data pgx.TraceCopyFromEndData) {
+	endSpan(traceCore.SpanFromContext(ctx), data.CommandTag, data.Err)
+}
+
+func (t *Tracer) start(ctx context.Context, conn *pgx.Conn, name string, attributes ...attribute.KeyValue) (context.Context, traceCore.Span) {
+	return t.tracer.Start(ctx, name,
+		traceCore.WithSpanKind(traceCore.SpanKindClient),
+		traceCore.WithAttributes(connAttributes(conn)...),
+		traceCore.WithAttributes(t.attributes...),
+		traceCore.WithAttributes(attributes...),
+	)
+}
+
+// statementAttributes names the span after the normalized statement, like
+// sqlparser.PostgresqlSpanFormatter, unless ctx overrides the name with
+// sqlparser.WithQueryName or sqlparser.WithoutNormalization. Statements that
+// are not parsed are not recorded, so that their literals do not leak.
+func (t *Tracer) statementAttributes(ctx context.Context, method string, query string) (string, []attribute.KeyValue) {
+	if name, overridden := sqlparser.ContextSpanName(ctx, method); overridden {
+		return name, nil
+	}
+	info, err := t.policy.StatementInfo("postgresql", query)
+	if err != nil {
+		return method, nil
+	}
+	return sqlparser.SpanName(info.Normalized), info.Attributes()
+}
+
+func connAttributes(conn *pgx.Conn) []attribute.KeyValue {
+	attributes := []attribute.KeyValue{attribute.String(sqlparser.DBSystemAttribute, "postgresql")}
+	if conn == nil {
+		return attributes
+	}
+	config := conn.Config()
+	return append(attributes,
+		attribute.String("db.name", config.Database),
+		attribute.String("db.user", config.User),
+		attribute.String("net.peer.name", config.Host),
+		attribute.String("net.peer.port", strconv.Itoa(int(config.Port))),
+	)
+}
+
+func endSpan(span traceCore.Span, commandTag pgconn.CommandTag, err error) {
+	if err != nil {
+		// the messages of PostgreSQL errors quote the values they reject, so
+		// only the SQLSTATE, or the type of other errors, is recorded
+		errorType := fmt.Sprintf("%T", err)
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) {
+			span.SetAttributes(attribute.String(StatusCodeAttribute, pgErr.Code))
+			errorType = "SQLSTATE " + pgErr.Code
+		}
+		span.AddEvent(exceptionEventName, traceCore.WithAttributes(attribute.String(exceptionTypeAttribute, errorType)))
+		span.SetStatus(codes.Error, errorType)
+	} else if commandTag.String() != "" {
+		span.SetAttributes(attribute.Int64(sqlparser.DBRowsAffectedAttribute, commandTag.RowsAffected()))
+	}
+	span.End()
+}
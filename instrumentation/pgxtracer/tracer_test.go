@@ -0,0 +1,118 @@
+package pgxtracer
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+
+	"github.com/coralogix/coralogix-opentelemetry-go/cgxtest"
+	sqlparser "github.com/coralogix/coralogix-opentelemetry-go/processor/sql"
+)
+
+func TestTracer_Query(t *testing.T) {
+	recorder := cgxtest.NewRecorder(t)
+	tracer := NewTracer(WithTracerProvider(recorder.TracerProvider()))
+	ctx, parent := recorder.Tracer("test").Start(context.Background(), "GET /users")
+
+	queryCtx := tracer.TraceQueryStart(ctx, nil, pgx.TraceQueryStartData{SQL: "SELECT * FROM users WHERE id = $1 AND name = 'Alice'"})
+	tracer.TraceQueryEnd(queryCtx, nil, pgx.TraceQueryEndData{CommandTag: pgconn.NewCommandTag("SELECT 1")})
+	parent.End()
+
+	assert.Len(t, recorder.Ended(), 2)
+	span := recorder.Span("SELECT * FROM users WHERE (id = $1) AND (name = '?')")
+	attributes := recorder.Attributes(span)
+	assert.Equal(t, span.Name(), attributes[sqlparser.DBStatementAttribute].AsString())
+	assert.Equal(t, "SELECT", attributes[sqlparser.DBOperationAttribute].AsString())
+	assert.Equal(t, "users", attributes[sqlparser.DBTableAttribute].AsString())
+	assert.Equal(t, "postgresql", attributes[sqlparser.DBSystemAttribute].AsString())
+	assert.Equal(t, int64(1), attributes[sqlparser.DBRowsAffectedAttribute].AsInt64())
+	recorder.AssertTransaction(span, "GET /users").AssertNotRoot(span)
+	assert.Equal(t, parent.SpanContext().SpanID(), span.Parent().SpanID())
+}
+
+func TestTracer_QueryError(t *testing.T) {
+	recorder := cgxtest.NewRecorder(t)
+	tracer := NewTracer(WithTracerProvider(recorder.TracerProvider()))
+
+	ctx := tracer.TraceQueryStart(context.Background(), nil, pgx.TraceQueryStartData{SQL: "INSERT INTO users (id) VALUES (1)"})
+	tracer.TraceQueryEnd(ctx, nil, pgx.TraceQueryEndData{Err: &pgconn.PgError{Code: "22P02", Message: `invalid input syntax for type integer: "alice@example.com"`}})
+
+	span := recorder.Span("INSERT INTO users(id) VALUES ('?')")
+	assert.Equal(t, codes.Error, span.Status().Code)
+	assert.Equal(t, "SQLSTATE 22P02", span.Status().Description)
+	assert.Equal(t, "22P02", recorder.Attributes(span)[StatusCodeAttribute].AsString())
+	require.Len(t, span.Events(), 1)
+	assert.Equal(t, []attribute.KeyValue{attribute.String("exception.type", "SQLSTATE 22P02")}, span.Events()[0].Attributes)
+}
+
+func TestTracer_UnparsableQuery(t *testing.T) {
+	recorder := cgxtest.NewRecorder(t)
+	tracer := NewTracer(WithTracerProvider(recorder.TracerProvider()))
+
+	ctx := tracer.TraceQueryStart(context.Background(), nil, pgx.TraceQueryStartData{SQL: "SELEC secret FROM"})
+	tracer.TraceQueryEnd(ctx, nil, pgx.TraceQueryEndData{Err: errors.New("syntax error")})
+
+	span := recorder.Span(querySpanName)
+	_, ok := recorder.Attributes(span)[sqlparser.DBStatementAttribute]
+	assert.False(t, ok)
+}
+
+func TestTracer_ContextOverrides(t *testing.T) {
+	recorder := cgxtest.NewRecorder(t)
+	tracer := NewTracer(WithTracerProvider(recorder.TracerProvider()))
+
+	ctx := tracer.TraceQueryStart(sqlparser.WithQueryName(context.Background(), "GetUser"), nil, pgx.TraceQueryStartData{SQL: "SELECT * FROM users WHERE name = 'Alice'"})
+	tracer.TraceQueryEnd(ctx, nil, pgx.TraceQueryEndData{})
+	ctx = tracer.TraceQueryStart(sqlparser.WithoutNormalization(context.Background()), nil, pgx.TraceQueryStartData{SQL: "SELECT * FROM users WHERE name = 'Alice'"})
+	tracer.TraceQueryEnd(ctx, nil, pgx.TraceQueryEndData{})
+
+	for _, span := range []string{"GetUser", querySpanName} {
+		_, ok := recorder.Attributes(recorder.Span(span))[sqlparser.DBStatementAttribute]
+		assert.False(t, ok, span)
+	}
+}
+
+func TestTracer_Batch(t *testing.T) {
+	recorder := cgxtest.NewRecorder(t)
+	tracer := NewTracer(WithTracerProvider(recorder.TracerProvider()))
+	batch := &pgx.Batch{}
+	batch.Queue("INSERT INTO users (id) VALUES ($1)", 1)
+	batch.Queue("INSERT INTO users (id) VALUES ($1)", 2)
+
+	ctx := tracer.TraceBatchStart(context.Background(), nil, pgx.TraceBatchStartData{Batch: batch})
+	tracer.TraceBatchQuery(ctx, nil, pgx.TraceBatchQueryData{SQL: "INSERT INTO users (id) VALUES ($1)", CommandTag: pgconn.NewCommandTag("INSERT 0 1")})
+	tracer.TraceBatchQuery(ctx, nil, pgx.TraceBatchQueryData{SQL: "INSERT INTO users (id) VALUES ($1)", CommandTag: pgconn.NewCommandTag("INSERT 0 1")})
+	tracer.TraceBatchEnd(ctx, nil, pgx.TraceBatchEndData{})
+
+	spans := recorder.Ended()
+	assert.Len(t, spans, 3)
+	batchSpan := recorder.Span(batchSpanName)
+	assert.Equal(t, int64(2), recorder.Attributes(batchSpan)[BatchSizeAttribute].AsInt64())
+	for _, span := range spans[:2] {
+		assert.Equal(t, "INSERT INTO users(id) VALUES ($1)", span.Name())
+		assert.Equal(t, batchSpan.SpanContext().SpanID(), span.Parent().SpanID())
+	}
+}
+
+func TestTracer_CopyFrom(t *testing.T) {
+	recorder := cgxtest.NewRecorder(t)
+	tracer := NewTracer(WithTracerProvider(recorder.TracerProvider()))
+
+	ctx := tracer.TraceCopyFromStart(context.Background(), nil, pgx.TraceCopyFromStartData{
+		TableName:   pgx.Identifier{"public", "users"},
+		ColumnNames: []string{"id", "name"},
+	})
+	tracer.TraceCopyFromEnd(ctx, nil, pgx.TraceCopyFromEndData{CommandTag: pgconn.NewCommandTag("COPY 500")})
+
+	span := recorder.Span("COPY public.users(id, name) FROM STDIN")
+	attributes := recorder.Attributes(span)
+	assert.Equal(t, "public.users", attributes[sqlparser.DBTableAttribute].AsString())
+	assert.Equal(t, int64(500), attributes[sqlparser.DBRowsAffectedAttribute].AsInt64())
+}
@@ -0,0 +1,380 @@
+package sqltracer
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+
+	"go.opentelemetry.io/otel/attribute"
+	traceCore "go.opentelemetry.io/otel/trace"
+)
+
+var (
+	_ driver.Conn               = (*tracedConn)(nil)
+	_ driver.ConnBeginTx        = (*tracedConn)(nil)
+	_ driver.ConnPrepareContext = (*tracedConn)(nil)
+	_ driver.ExecerContext      = (*tracedConn)(nil)
+	_ driver.QueryerContext     = (*tracedConn)(nil)
+	_ driver.Pinger             = (*tracedConn)(nil)
+	_ driver.SessionResetter    = (*tracedConn)(nil)
+	_ driver.Validator          = (*tracedConn)(nil)
+	_ driver.NamedValueChecker  = (*tracedConn)(nil)
+)
+
+type tracedConn struct {
+	conn   driver.Conn
+	tracer *tracer
+}
+
+func (c *tracedConn) Prepare(query string) (driver.Stmt, error) {
+	return c.PrepareContext(context.Background(), query)
+}
+
+func (c *tracedConn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	ctx, span := c.tracer.startStatement(ctx, "sql.conn.prepare", query)
+	var stmt driver.Stmt
+	var err error
+	if preparer, ok := c.conn.(driver.ConnPrepareContext); ok {
+		stmt, err = preparer.PrepareContext(ctx, query)
+	} else {
+		stmt, err = c.conn.Prepare(query)
+	}
+	endSpan(span, err)
+	if err != nil {
+		return nil, err
+	}
+	return &tracedStmt{stmt: stmt, conn: c.conn, query: query, tracer: c.tracer}, nil
+}
+
+func (c *tracedConn) Close() error {
+	return c.conn.Close()
+}
+
+func (c *tracedConn) Begin() (driver.Tx, error) {
+	return c.BeginTx(context.Background(), driver.TxOptions{})
+}
+
+func (c *tracedConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	beginCtx, span := c.tracer.start(ctx, "sql.conn.begin_tx")
+	var tx driver.Tx
+	var err error
+	if beginner, ok := c.conn.(driver.ConnBeginTx); ok {
+		tx, err = beginner.BeginTx(beginCtx, opts)
+	} else {
+		tx, err = c.conn.Begin()
+	}
+	endSpan(span, err)
+	if err != nil {
+		return nil, err
+	}
+	return &tracedTx{tx: tx, ctx: ctx, tracer: c.tracer}, nil
+}
+
+// ExecContext returns driver.ErrSkip when the wrapped connection cannot execute
+// directly, so that database/sql falls back to a prepared statement.
+func (c *tracedConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	execer, ok := c.conn.(driver.ExecerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+	ctx, span := c.tracer.startStatement(ctx, "sql.conn.exec", query)
+	result, err := execer.ExecContext(ctx, query, args)
+	if errors.Is(err, driver.ErrSkip) {
+		// database/sql retries with a prepared statement, traced on its own:
+		// the span is ended, as span processors saw it start, but not failed
+		span.End()
+		return nil, err
+	}
+	endExecSpan(span, result, err)
+	return result, err
+}
+
+func (c *tracedConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	queryer, ok := c.conn.(driver.QueryerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+	queryCtx, span := c.tracer.startStatement(ctx, "sql.conn.query", query)
+	rows, err := queryer.QueryContext(queryCtx, query, args)
+	if errors.Is(err, driver.ErrSkip) {
+		// ended without an error like the span of ExecContext
+		span.End()
+		return nil, err
+	}
+	endSpan(span, err)
+	if err != nil {
+		return nil, err
+	}
+	return c.tracer.wrapRows(queryCtx, rows), nil
+}
+
+func (c *tracedConn) Ping(ctx context.Context) error {
+	pinger, ok := c.conn.(driver.Pinger)
+	if !ok {
+		return nil
+	}
+	ctx, span := c.tracer.start(ctx, "sql.conn.ping")
+	err := pinger.Ping(ctx)
+	endSpan(span, err)
+	return err
+}
+
+func (c *tracedConn) ResetSession(ctx context.Context) error {
+	if resetter, ok := c.conn.(driver.SessionResetter); ok {
+		return resetter.ResetSession(ctx)
+	}
+	return nil
+}
+
+func (c *tracedConn) IsValid() bool {
+	if validator, ok := c.conn.(driver.Validator); ok {
+		return validator.IsValid()
+	}
+	return true
+}
+
+func (c *tracedConn) CheckNamedValue(value *driver.NamedValue) error {
+	if checker, ok := c.conn.(driver.NamedValueChecker); ok {
+		return checker.CheckNamedValue(value)
+	}
+	return driver.ErrSkip
+}
+
+var (
+	_ driver.Stmt              = (*tracedStmt)(nil)
+	_ driver.StmtExecContext   = (*tracedStmt)(nil)
+	_ driver.StmtQueryContext  = (*tracedStmt)(nil)
+	_ driver.NamedValueChecker = (*tracedStmt)(nil)
+)
+
+type tracedStmt struct {
+	stmt   driver.Stmt
+	conn   driver.Conn
+	query  string
+	tracer *tracer
+}
+
+func (s *tracedStmt) Close() error {
+	return s.stmt.Close()
+}
+
+func (s *tracedStmt) NumInput() int {
+	return s.stmt.NumInput()
+}
+
+func (s *tracedStmt) Exec(args []driver.Value) (driver.Result, error) {
+	return s.ExecContext(context.Background(), namedValues(args))
+}
+
+func (s *tracedStmt) Query(args []driver.Value) (driver.Rows, error) {
+	return s.QueryContext(context.Background(), namedValues(args))
+}
+
+func (s *tracedStmt) ExecContext(ctx context.Context, args []driver.NamedValue) (driver.Result, error) {
+	ctx, span := s.tracer.startStatement(ctx, "sql.stmt.exec", s.query)
+	var result driver.Result
+	var err error
+	if execer, ok := s.stmt.(driver.StmtExecContext); ok {
+		result, err = execer.ExecContext(ctx, args)
+	} else if values, convertErr := driverValues(args); convertErr != nil {
+		err = convertErr
+	} else {
+		result, err = s.stmt.Exec(values)
+	}
+	endExecSpan(span, result, err)
+	return result, err
+}
+
+func (s *tracedStmt) QueryContext(ctx context.Context, args []driver.NamedValue) (driver.Rows, error) {
+	queryCtx, span := s.tracer.startStatement(ctx, "sql.stmt.query", s.query)
+	var rows driver.Rows
+	var err error
+	if queryer, ok := s.stmt.(driver.StmtQueryContext); ok {
+		rows, err = queryer.QueryContext(queryCtx, args)
+	} else if values, convertErr := driverValues(args); convertErr != nil {
+		err = convertErr
+	} else {
+		rows, err = s.stmt.Query(values)
+	}
+	endSpan(span, err)
+	if err != nil {
+		return nil, err
+	}
+	return s.tracer.wrapRows(queryCtx, rows), nil
+}
+
+// CheckNamedValue checks arguments as database/sql does for the wrapped
+// statement: with its NamedValueChecker or the one of its connection, then with
+// its ColumnConverter, before the default conversion.
+func (s *tracedStmt) CheckNamedValue(value *driver.NamedValue) error {
+	err := driver.ErrSkip
+	if checker, ok := s.stmt.(driver.NamedValueChecker); ok {
+		err = checker.CheckNamedValue(value)
+	} else if checker, ok := s.conn.(driver.NamedValueChecker); ok {
+		err = checker.CheckNamedValue(value)
+	}
+	if err != driver.ErrSkip {
+		return err
+	}
+	if converter, ok := s.stmt.(driver.ColumnConverter); ok {
+		return convertColumn(converter, s.stmt.NumInput(), value)
+	}
+	return driver.ErrSkip
+}
+
+// convertColumn converts value with the converter of its column, as
+// database/sql does for statements implementing driver.ColumnConverter.
+func convertColumn(converter driver.ColumnConverter, inputs int, value *driver.NamedValue) error {
+	index := value.Ordinal - 1
+	if inputs <= index {
+		// the argument count is checked by database/sql
+		return nil
+	}
+	if valuer, ok := value.Value.(driver.Valuer); ok {
+		v, err := valuer.Value()
+		if err != nil {
+			return err
+		}
+		value.Value = v
+	}
+	v, err := converter.ColumnConverter(index).ConvertValue(value.Value)
+	if err != nil {
+		return err
+	}
+	if !driver.IsValue(v) {
+		return fmt.Errorf("sql: driver ColumnConverter error converted %T to unsupported type %T", value.Value, v)
+	}
+	value.Value = v
+	return nil
+}
+
+func namedValues(args []driver.Value) []driver.NamedValue {
+	named := make([]driver.NamedValue, len(args))
+	for i, arg := range args {
+		named[i] = driver.NamedValue{Ordinal: i + 1, Value: arg}
+	}
+	return named
+}
+
+func driverValues(args []driver.NamedValue) ([]driver.Value, error) {
+	values := make([]driver.Value, len(args))
+	for i, arg := range args {
+		if arg.Name != "" {
+			return nil, errors.New("sql: driver does not support the use of Named Parameters")
+		}
+		values[i] = arg.Value
+	}
+	return values, nil
+}
+
+// tracedTx keeps the context the transaction was started from, so that
+// commit and rollback spans are siblings of the begin span.
+type tracedTx struct {
+	tx     driver.Tx
+	ctx    context.Context
+	tracer *tracer
+}
+
+func (t *tracedTx) Commit() error {
+	_, span := t.tracer.start(t.ctx, "sql.tx.commit")
+	err := t.tx.Commit()
+	endSpan(span, err)
+	return err
+}
+
+func (t *tracedTx) Rollback() error {
+	_, span := t.tracer.start(t.ctx, "sql.tx.rollback")
+	err := t.tx.Rollback()
+	endSpan(span, err)
+	return err
+}
+
+// tracedRows records a span from the end of the query until the rows are
+// closed, with the number of rows read.
+type tracedRows struct {
+	driver.Rows
+	span     traceCore.Span
+	returned int64
+	err      error
+}
+
+func (t *tracer) wrapRows(ctx context.Context, rows driver.Rows) driver.Rows {
+	_, span := t.start(ctx, "sql.rows")
+	return &tracedRows{Rows: rows, span: span}
+}
+
+func (r *tracedRows) Next(dest []driver.Value) error {
+	err := r.Rows.Next(dest)
+	if err == nil {
+		r.returned++
+	} else if err != io.EOF {
+		r.err = err
+	}
+	return err
+}
+
+func (r *tracedRows) Close() error {
+	err := r.Rows.Close()
+	r.span.SetAttributes(attribute.Int64(ReturnedRowsAttribute, r.returned))
+	if r.err != nil {
+		endSpan(r.span, r.err)
+	} else {
+		endSpan(r.span, err)
+	}
+	return err
+}
+
+// The optional Rows interfaces are forwarded, falling back to the values
+// database/sql uses when a driver does not implement them.
+
+func (r *tracedRows) HasNextResultSet() bool {
+	if rows, ok := r.Rows.(driver.RowsNextResultSet); ok {
+		return rows.HasNextResultSet()
+	}
+	return false
+}
+
+func (r *tracedRows) NextResultSet() error {
+	if rows, ok := r.Rows.(driver.RowsNextResultSet); ok {
+		return rows.NextResultSet()
+	}
+	return io.EOF
+}
+
+func (r *tracedRows) ColumnTypeScanType(index int) reflect.Type {
+	if rows, ok := r.Rows.(driver.RowsColumnTypeScanType); ok {
+		return rows.ColumnTypeScanType(index)
+	}
+	return reflect.TypeOf(new(any)).Elem()
+}
+
+func (r *tracedRows) ColumnTypeDatabaseTypeName(index int) string {
+	if rows, ok := r.Rows.(driver.RowsColumnTypeDatabaseTypeName); ok {
+		return rows.ColumnTypeDatabaseTypeName(index)
+	}
+	return ""
+}
+
+func (r *tracedRows) ColumnTypeLength(index int) (int64, bool) {
+	if rows, ok := r.Rows.(driver.RowsColumnTypeLength); ok {
+		return rows.ColumnTypeLength(index)
+	}
+	return 0, false
+}
+
+func (r *tracedRows) ColumnTypeNullable(index int) (bool, bool) {
+	if rows, ok := r.Rows.(driver.RowsColumnTypeNullable); ok {
+		return rows.ColumnTypeNullable(index)
+	}
+	return false, false
+}
+
+func (r *tracedRows) ColumnTypePrecisionScale(index int) (int64, int64, bool) {
+	if rows, ok := r.Rows.(driver.RowsColumnTypePrecisionScale); ok {
+		return rows.ColumnTypePrecisionScale(index)
+	}
+	return 0, 0, false
+}
@@ -0,0 +1,176 @@
+package sqltracer
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"strings"
+)
+
+// fakeDriver is an in-process driver answering every query with the rows of
+// fakeRowsCount and every exec with fakeRowsAffected. Queries containing "fail"
+// return errFake, queries containing "slow" wait for the context to be done and
+// queries containing "skip" are only run as prepared statements. Connections
+// accept fakeID arguments, which the default conversion rejects.
+type fakeDriver struct{}
+
+const (
+	fakeRowsCount    = 3
+	fakeRowsAffected = 2
+)
+
+var errFake = errors.New("fake driver failure")
+
+// fakeID is an argument type only the connections of fakeDriver accept.
+type fakeID struct {
+	id int
+}
+
+func init() {
+	sql.Register("fake", fakeDriver{})
+	sql.Register("fake-connector", fakeConnectorDriver{})
+}
+
+func (fakeDriver) Open(string) (driver.Conn, error) {
+	return &fakeConn{}, nil
+}
+
+// fakeConnectorDriver is a fakeDriver opened through connectors, which reject
+// an empty data source name.
+type fakeConnectorDriver struct {
+	fakeDriver
+}
+
+func (d fakeConnectorDriver) OpenConnector(name string) (driver.Connector, error) {
+	if name == "" {
+		return nil, errors.New("fake: empty data source name")
+	}
+	return fakeConnector{driver: d}, nil
+}
+
+type fakeConnector struct {
+	driver fakeConnectorDriver
+}
+
+func (c fakeConnector) Connect(context.Context) (driver.Conn, error) {
+	return &fakeConn{}, nil
+}
+
+func (c fakeConnector) Driver() driver.Driver {
+	return c.driver
+}
+
+type fakeConn struct{}
+
+func (c *fakeConn) Prepare(query string) (driver.Stmt, error) {
+	return &fakeStmt{query: query}, nil
+}
+
+func (c *fakeConn) Close() error {
+	return nil
+}
+
+func (c *fakeConn) Begin() (driver.Tx, error) {
+	return fakeTx{}, nil
+}
+
+func (c *fakeConn) BeginTx(context.Context, driver.TxOptions) (driver.Tx, error) {
+	return fakeTx{}, nil
+}
+
+func (c *fakeConn) CheckNamedValue(value *driver.NamedValue) error {
+	if _, ok := value.Value.(fakeID); ok {
+		return nil
+	}
+	return driver.ErrSkip
+}
+
+func (c *fakeConn) ExecContext(ctx context.Context, query string, _ []driver.NamedValue) (driver.Result, error) {
+	if strings.Contains(query, "skip") {
+		return nil, driver.ErrSkip
+	}
+	if err := fakeQueryError(ctx, query); err != nil {
+		return nil, err
+	}
+	return driver.RowsAffected(fakeRowsAffected), nil
+}
+
+func (c *fakeConn) QueryContext(ctx context.Context, query string, _ []driver.NamedValue) (driver.Rows, error) {
+	if strings.Contains(query, "skip") {
+		return nil, driver.ErrSkip
+	}
+	if err := fakeQueryError(ctx, query); err != nil {
+		return nil, err
+	}
+	return &fakeRows{}, nil
+}
+
+func fakeQueryError(ctx context.Context, query string) error {
+	if strings.Contains(query, "slow") {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+	if strings.Contains(query, "fail") {
+		return errFake
+	}
+	return nil
+}
+
+type fakeStmt struct {
+	query string
+}
+
+func (s *fakeStmt) Close() error {
+	return nil
+}
+
+func (s *fakeStmt) NumInput() int {
+	return -1
+}
+
+func (s *fakeStmt) Exec([]driver.Value) (driver.Result, error) {
+	if err := fakeQueryError(context.Background(), s.query); err != nil {
+		return nil, err
+	}
+	return driver.RowsAffected(fakeRowsAffected), nil
+}
+
+func (s *fakeStmt) Query([]driver.Value) (driver.Rows, error) {
+	if err := fakeQueryError(context.Background(), s.query); err != nil {
+		return nil, err
+	}
+	return &fakeRows{}, nil
+}
+
+type fakeTx struct{}
+
+func (fakeTx) Commit() error {
+	return nil
+}
+
+func (fakeTx) Rollback() error {
+	return nil
+}
+
+type fakeRows struct {
+	read int
+}
+
+func (r *fakeRows) Columns() []string {
+	return []string{"id"}
+}
+
+func (r *fakeRows) Close() error {
+	return nil
+}
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if r.read == fakeRowsCount {
+		return io.EOF
+	}
+	r.read++
+	dest[0] = int64(r.read)
+	return nil
+}
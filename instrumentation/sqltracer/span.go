@@ -0,0 +1,93 @@
+package sqltracer
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	traceCore "go.opentelemetry.io/otel/trace"
+
+	sqlparser "github.com/coralogix/coralogix-opentelemetry-go/processor/sql"
+)
+
+const (
+	ErrorTypeAttribute    = "error.type"
+	ReturnedRowsAttribute = "db.response.returned_rows"
+
+	exceptionEventName     = "exception"
+	exceptionTypeAttribute = "exception.type"
+)
+
+// Error classes recorded in the error.type attribute.
+const (
+	ErrorTypeCanceled      = "canceled"
+	ErrorTypeTimeout       = "timeout"
+	ErrorTypeBadConnection = "bad_connection"
+	ErrorTypeTxDone        = "tx_done"
+	ErrorTypeDriver        = "driver_error"
+)
+
+// ClassifyError returns the error.type recorded for err.
+func ClassifyError(err error) string {
+	switch {
+	case errors.Is(err, context.Canceled):
+		return ErrorTypeCanceled
+	case errors.Is(err, context.DeadlineExceeded):
+		return ErrorTypeTimeout
+	case errors.Is(err, driver.ErrBadConn):
+		return ErrorTypeBadConnection
+	case errors.Is(err, sql.ErrTxDone):
+		return ErrorTypeTxDone
+	}
+	return ErrorTypeDriver
+}
+
+func (t *tracer) start(ctx context.Context, name string, attributes ...attribute.KeyValue) (context.Context, traceCore.Span) {
+	return t.tracer.Start(ctx, name,
+		traceCore.WithSpanKind(traceCore.SpanKindClient),
+		traceCore.WithAttributes(t.attributes...),
+		traceCore.WithAttributes(attributes...),
+	)
+}
+
+// startStatement names the span after the normalized statement, like the
+// processor/sql span formatters, and falls back to method when the dialect is
+// unknown or the statement cannot be parsed, so that literals do not leak. The
+// overrides of sqlparser.WithQueryName and sqlparser.WithoutNormalization
+// name the span without parsing the statement.
+func (t *tracer) startStatement(ctx context.Context, method string, query string) (context.Context, traceCore.Span) {
+	if name, overridden := sqlparser.ContextSpanName(ctx, method); overridden {
+		return t.start(ctx, name)
+	}
+	if t.statementInfo != nil && query != "" {
+		if info, err := t.statementInfo(query); err == nil {
+			return t.start(ctx, sqlparser.SpanName(info.Normalized), info.Attributes()...)
+		}
+	}
+	return t.start(ctx, method)
+}
+
+func endSpan(span traceCore.Span, err error) {
+	if err != nil {
+		// driver messages may quote the values of the rows, so only the class
+		// and the type of the error are recorded
+		errorType := ClassifyError(err)
+		span.SetAttributes(attribute.String(ErrorTypeAttribute, errorType))
+		span.AddEvent(exceptionEventName, traceCore.WithAttributes(attribute.String(exceptionTypeAttribute, fmt.Sprintf("%T", err))))
+		span.SetStatus(codes.Error, errorType)
+	}
+	span.End()
+}
+
+func endExecSpan(span traceCore.Span, result driver.Result, err error) {
+	if err == nil && result != nil {
+		if rowsAffected, err := result.RowsAffected(); err == nil {
+			span.SetAttributes(attribute.Int64(sqlparser.DBRowsAffectedAttribute, rowsAffected))
+		}
+	}
+	endSpan(span, err)
+}
@@ -0,0 +1,263 @@
+// Package sqltracer wraps database/sql drivers to trace connections, statements,
+// transactions and rows with statements normalized by the processor/sql dialects.
+package sqltracer
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"sync"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	traceCore "go.opentelemetry.io/otel/trace"
+
+	sqlparser "github.com/coralogix/coralogix-opentelemetry-go/processor/sql"
+)
+
+const instrumentationName = "github.com/coralogix/coralogix-opentelemetry-go/instrumentation/sqltracer"
+
+type config struct {
+	tracerProvider traceCore.TracerProvider
+	dbSystem       string
+	attributes     []attribute.KeyValue
+	policy         *sqlparser.RedactionPolicy
+}
+
+type Option func(*config)
+
+// WithTracerProvider sets the provider of the connection, statement,
+// transaction and rows spans, otel.GetTracerProvider() by default.
+func WithTracerProvider(provider traceCore.TracerProvider) Option {
+	return func(c *config) {
+		c.tracerProvider = provider
+	}
+}
+
+// WithDBSystem sets the db.system attribute and selects the dialect used to
+// normalize statements, e.g. "mysql" or "postgresql". Statements of systems
+// without a registered dialect are not recorded.
+func WithDBSystem(dbSystem string) Option {
+	return func(c *config) {
+		c.dbSystem = dbSystem
+	}
+}
+
+// WithAttributes adds attributes, e.g. db.name, to the spans of every
+// connection of the database.
+func WithAttributes(attributes ...attribute.KeyValue) Option {
+	return func(c *config) {
+		c.attributes = append(c.attributes, attributes...)
+	}
+}
+
+// WithRedactionPolicy applies policy to the statements of the WithDBSystem
+// dialect, keeping the literals of the columns it allows.
+func WithRedactionPolicy(policy sqlparser.RedactionPolicy) Option {
+	return func(c *config) {
+		c.policy = &policy
+	}
+}
+
+// tracer holds what the wrapped driver, connections and statements share.
+type tracer struct {
+	tracer        traceCore.Tracer
+	attributes    []attribute.KeyValue
+	statementInfo func(string) (sqlparser.StatementInfo, error)
+}
+
+func newTracer(options []Option) *tracer {
+	c := &config{}
+	for _, option := range options {
+		option(c)
+	}
+	if c.tracerProvider == nil {
+		c.tracerProvider = otel.GetTracerProvider()
+	}
+	t := &tracer{tracer: c.tracerProvider.Tracer(instrumentationName)}
+	if c.dbSystem != "" {
+		t.attributes = append(t.attributes, attribute.String(sqlparser.DBSystemAttribute, c.dbSystem))
+		t.statementInfo, _ = sqlparser.DialectStatementInfo(c.dbSystem)
+		if policy := c.policy; policy != nil && t.statementInfo != nil {
+			t.statementInfo = func(query string) (sqlparser.StatementInfo, error) {
+				return policy.StatementInfo(c.dbSystem, query)
+			}
+		}
+	}
+	t.attributes = append(t.attributes, c.attributes...)
+	return t
+}
+
+// WrapDriver returns a driver tracing every operation of d.
+func WrapDriver(d driver.Driver, options ...Option) driver.Driver {
+	return wrapDriver(d, newTracer(options))
+}
+
+var (
+	registerLock sync.Mutex
+	registered   = map[string]int{}
+)
+
+// Register registers a traced copy of the driver registered as driverName and
+// returns the name to pass to sql.Open.
+func Register(driverName string, options ...Option) (string, error) {
+	if !slices.Contains(sql.Drivers(), driverName) {
+		return "", fmt.Errorf("sql: unknown driver %q (forgotten import?)", driverName)
+	}
+	registerLock.Lock()
+	defer registerLock.Unlock()
+	registered[driverName]++
+	name := fmt.Sprintf("%s-cx-%d", driverName, registered[driverName])
+	sql.Register(name, &registeredDriver{driverName: driverName, tracer: newTracer(options)})
+	return name, nil
+}
+
+// Open opens a database with the driver registered as driverName, tracing every operation.
+func Open(driverName string, dataSourceName string, options ...Option) (*sql.DB, error) {
+	d, err := lookupDriver(driverName, dataSourceName)
+	if err != nil {
+		return nil, err
+	}
+	t := newTracer(options)
+	if driverContext, ok := d.(driver.DriverContext); ok {
+		connector, err := driverContext.OpenConnector(dataSourceName)
+		if err != nil {
+			return nil, err
+		}
+		return sql.OpenDB(&tracedConnector{Connector: connector, driver: wrapDriver(d, t), tracer: t}), nil
+	}
+	return sql.OpenDB(&dsnConnector{dsn: dataSourceName, driver: wrapDriver(d, t)}), nil
+}
+
+// OpenDB opens a database from a connector, tracing every operation.
+func OpenDB(connector driver.Connector, options ...Option) *sql.DB {
+	t := newTracer(options)
+	return sql.OpenDB(&tracedConnector{Connector: connector, driver: wrapDriver(connector.Driver(), t), tracer: t})
+}
+
+// lookupDriver returns the driver registered as driverName. sql.Open does not
+// connect, it only resolves the driver and, for a driver.DriverContext, parses
+// dataSourceName, which is therefore the one the database is opened with.
+func lookupDriver(driverName string, dataSourceName string) (driver.Driver, error) {
+	db, err := sql.Open(driverName, dataSourceName)
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+	if db.Driver() == nil {
+		return nil, errors.New("sql: driver " + driverName + " is nil")
+	}
+	return db.Driver(), nil
+}
+
+// registeredDriver is the traced copy of a driver returned by Register. The
+// driver is looked up with the data source name database/sql opens it with,
+// so that drivers rejecting an empty name can be registered.
+type registeredDriver struct {
+	driverName string
+	tracer     *tracer
+}
+
+func (d *registeredDriver) Open(name string) (driver.Conn, error) {
+	wrapped, err := d.lookup(name)
+	if err != nil {
+		return nil, err
+	}
+	return wrapped.Open(name)
+}
+
+func (d *registeredDriver) OpenConnector(name string) (driver.Connector, error) {
+	wrapped, err := d.lookup(name)
+	if err != nil {
+		return nil, err
+	}
+	if driverContext, ok := wrapped.(driver.DriverContext); ok {
+		return driverContext.OpenConnector(name)
+	}
+	return &dsnConnector{dsn: name, driver: wrapped}, nil
+}
+
+func (d *registeredDriver) lookup(name string) (driver.Driver, error) {
+	inner, err := lookupDriver(d.driverName, name)
+	if err != nil {
+		return nil, err
+	}
+	return wrapDriver(inner, d.tracer), nil
+}
+
+type tracedDriver struct {
+	driver driver.Driver
+	tracer *tracer
+}
+
+func wrapDriver(d driver.Driver, t *tracer) driver.Driver {
+	if driverContext, ok := d.(driver.DriverContext); ok {
+		return &tracedDriverContext{tracedDriver: tracedDriver{driver: d, tracer: t}, driverContext: driverContext}
+	}
+	return &tracedDriver{driver: d, tracer: t}
+}
+
+func (d *tracedDriver) Open(name string) (driver.Conn, error) {
+	conn, err := d.driver.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	return &tracedConn{conn: conn, tracer: d.tracer}, nil
+}
+
+type tracedDriverContext struct {
+	tracedDriver
+	driverContext driver.DriverContext
+}
+
+func (d *tracedDriverContext) OpenConnector(name string) (driver.Connector, error) {
+	connector, err := d.driverContext.OpenConnector(name)
+	if err != nil {
+		return nil, err
+	}
+	return &tracedConnector{Connector: connector, driver: d, tracer: d.tracer}, nil
+}
+
+type tracedConnector struct {
+	driver.Connector
+	driver driver.Driver
+	tracer *tracer
+}
+
+func (c *tracedConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	ctx, span := c.tracer.start(ctx, "sql.connector.connect")
+	conn, err := c.Connector.Connect(ctx)
+	endSpan(span, err)
+	if err != nil {
+		return nil, err
+	}
+	return &tracedConn{conn: conn, tracer: c.tracer}, nil
+}
+
+func (c *tracedConnector) Driver() driver.Driver {
+	return c.driver
+}
+
+type dsnConnector struct {
+	dsn    string
+	driver driver.Driver
+}
+
+func (c *dsnConnector) Connect(_ context.Context) (driver.Conn, error) {
+	return c.driver.Open(c.dsn)
+}
+
+func (c *dsnConnector) Driver() driver.Driver {
+	return c.driver
+}
+
+func (c *tracedConnector) Close() error {
+	if closer, ok := c.Connector.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
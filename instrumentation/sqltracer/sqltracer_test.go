@@ -0,0 +1,208 @@
+package sqltracer
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+
+	"github.com/coralogix/coralogix-opentelemetry-go/cgxtest"
+	sqlparser "github.com/coralogix/coralogix-opentelemetry-go/processor/sql"
+)
+
+// openTestDB opens a database over the fake driver, traced by recorder.
+func openTestDB(t *testing.T, recorder *cgxtest.Recorder, options ...Option) *sql.DB {
+	db, err := Open("fake", "dsn", append([]Option{WithTracerProvider(recorder.TracerProvider())}, options...)...)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = db.Close() })
+	return db
+}
+
+func TestOpen_Exec(t *testing.T) {
+	recorder := cgxtest.NewRecorder(t)
+	db := openTestDB(t, recorder, WithDBSystem("postgresql"))
+	ctx, parent := recorder.Tracer("test").Start(context.Background(), "POST /users")
+
+	_, err := db.ExecContext(ctx, "INSERT INTO users (id, name) VALUES (1, 'Alice'), (2, 'Bob')")
+	require.NoError(t, err)
+	parent.End()
+
+	span := recorder.Span("INSERT INTO users(id, name) VALUES ('?', '?')")
+	attributes := recorder.Attributes(span)
+	assert.Equal(t, "postgresql", attributes[sqlparser.DBSystemAttribute].AsString())
+	assert.Equal(t, "INSERT", attributes[sqlparser.DBOperationAttribute].AsString())
+	assert.Equal(t, "users", attributes[sqlparser.DBTableAttribute].AsString())
+	assert.Equal(t, int64(fakeRowsAffected), attributes[sqlparser.DBRowsAffectedAttribute].AsInt64())
+	recorder.AssertTransaction(span, "POST /users").AssertNotRoot(span)
+}
+
+func TestOpen_ContextOverrides(t *testing.T) {
+	recorder := cgxtest.NewRecorder(t)
+	db := openTestDB(t, recorder, WithDBSystem("mysql"))
+
+	_, err := db.ExecContext(sqlparser.WithQueryName(context.Background(), "DeleteUser"), "DELETE FROM users WHERE name = 'Alice'")
+	require.NoError(t, err)
+	_, err = db.ExecContext(sqlparser.WithoutNormalization(context.Background()), "DELETE FROM users WHERE name = 'Alice'")
+	require.NoError(t, err)
+
+	for _, span := range []string{"DeleteUser", "sql.conn.exec"} {
+		_, ok := recorder.Attributes(recorder.Span(span))[sqlparser.DBStatementAttribute]
+		assert.False(t, ok, span)
+	}
+}
+
+func TestOpen_Query(t *testing.T) {
+	recorder := cgxtest.NewRecorder(t)
+	db := openTestDB(t, recorder, WithDBSystem("mysql"))
+
+	rows, err := db.QueryContext(context.Background(), "SELECT id FROM users WHERE name = 'Alice'")
+	require.NoError(t, err)
+	count := 0
+	for rows.Next() {
+		count++
+	}
+	require.NoError(t, rows.Close())
+	assert.Equal(t, fakeRowsCount, count)
+
+	query := recorder.Span("select id from users where name = ?")
+	rowsSpan := recorder.Span("sql.rows")
+	assert.Equal(t, int64(fakeRowsCount), recorder.Attributes(rowsSpan)[ReturnedRowsAttribute].AsInt64())
+	assert.Equal(t, query.SpanContext().SpanID(), rowsSpan.Parent().SpanID())
+}
+
+func TestOpen_PreparedStatement(t *testing.T) {
+	recorder := cgxtest.NewRecorder(t)
+	db := openTestDB(t, recorder, WithDBSystem("mysql"))
+
+	stmt, err := db.PrepareContext(context.Background(), "UPDATE users SET name = ? WHERE id = 5")
+	require.NoError(t, err)
+	_, err = stmt.ExecContext(context.Background(), "Carol")
+	require.NoError(t, err)
+	require.NoError(t, stmt.Close())
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 2)
+	for _, span := range spans {
+		assert.Equal(t, "update users set name = ? where id = ?", span.Name())
+	}
+	assert.Equal(t, int64(fakeRowsAffected), recorder.Attributes(spans[1])[sqlparser.DBRowsAffectedAttribute].AsInt64())
+}
+
+func TestOpen_PreparedStatementConnArguments(t *testing.T) {
+	recorder := cgxtest.NewRecorder(t)
+	db := openTestDB(t, recorder, WithDBSystem("mysql"))
+
+	stmt, err := db.Prepare("DELETE FROM users WHERE id = ?")
+	require.NoError(t, err)
+	defer stmt.Close()
+	_, err = stmt.Exec(fakeID{id: 5})
+	require.NoError(t, err)
+	_, err = stmt.Exec(struct{}{})
+	assert.ErrorContains(t, err, "unsupported type")
+}
+
+func TestOpen_Transaction(t *testing.T) {
+	recorder := cgxtest.NewRecorder(t)
+	db := openTestDB(t, recorder)
+
+	tx, err := db.BeginTx(context.Background(), nil)
+	require.NoError(t, err)
+	_, err = tx.Exec("DELETE FROM sessions WHERE token = 'secret'")
+	require.NoError(t, err)
+	require.NoError(t, tx.Commit())
+
+	begin := recorder.Span("sql.conn.begin_tx")
+	commit := recorder.Span("sql.tx.commit")
+	assert.Equal(t, begin.Parent(), commit.Parent())
+	// without a db.system the statement cannot be normalized and is not recorded
+	exec := recorder.Span("sql.conn.exec")
+	_, ok := recorder.Attributes(exec)[sqlparser.DBStatementAttribute]
+	assert.False(t, ok)
+}
+
+func TestOpen_Errors(t *testing.T) {
+	recorder := cgxtest.NewRecorder(t)
+	db := openTestDB(t, recorder, WithDBSystem("postgresql"))
+
+	_, err := db.Exec("UPDATE fail SET a = 1")
+	assert.ErrorIs(t, err, errFake)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err = db.QueryContext(ctx, "SELECT * FROM slow")
+	assert.Error(t, err)
+
+	failed := recorder.Span("UPDATE fail SET a = '?'")
+	assert.Equal(t, codes.Error, failed.Status().Code)
+	assert.Equal(t, ErrorTypeDriver, recorder.Attributes(failed)[ErrorTypeAttribute].AsString())
+	assert.Equal(t, ErrorTypeDriver, failed.Status().Description)
+	require.Len(t, failed.Events(), 1)
+	assert.Equal(t, []attribute.KeyValue{attribute.String("exception.type", "*errors.errorString")}, failed.Events()[0].Attributes)
+	slow := recorder.Span("SELECT * FROM slow")
+	assert.Equal(t, ErrorTypeTimeout, recorder.Attributes(slow)[ErrorTypeAttribute].AsString())
+}
+
+func TestOpen_ErrSkip(t *testing.T) {
+	recorder := cgxtest.NewRecorder(t)
+	db := openTestDB(t, recorder, WithDBSystem("mysql"))
+
+	_, err := db.Exec("UPDATE skip SET a = 1")
+	require.NoError(t, err)
+	rows, err := db.Query("SELECT * FROM skip")
+	require.NoError(t, err)
+	require.NoError(t, rows.Close())
+
+	// the skipped call is ended without an error, then database/sql prepares
+	// the statement and runs it
+	var names []string
+	for _, span := range recorder.Ended() {
+		names = append(names, span.Name())
+		assert.NotEqual(t, codes.Error, span.Status().Code)
+	}
+	assert.Equal(t, []string{
+		"update skip set a = ?", "update skip set a = ?", "update skip set a = ?",
+		"select * from skip", "select * from skip", "select * from skip", "sql.rows",
+	}, names)
+}
+
+func TestRegister(t *testing.T) {
+	recorder := cgxtest.NewRecorder(t)
+	name, err := Register("fake", WithTracerProvider(recorder.TracerProvider()), WithDBSystem("mysql"))
+	require.NoError(t, err)
+	db, err := sql.Open(name, "dsn")
+	require.NoError(t, err)
+	defer db.Close()
+
+	_, err = db.Exec("DELETE FROM users WHERE id = 7")
+	require.NoError(t, err)
+	recorder.Span("delete from users where id = ?")
+
+	_, err = Register("missing")
+	assert.Error(t, err)
+}
+
+func TestOpen_DriverRejectingEmptyDataSourceName(t *testing.T) {
+	recorder := cgxtest.NewRecorder(t)
+	db, err := Open("fake-connector", "dsn", WithTracerProvider(recorder.TracerProvider()), WithDBSystem("mysql"))
+	require.NoError(t, err)
+	defer db.Close()
+	_, err = db.Exec("DELETE FROM users WHERE id = 7")
+	require.NoError(t, err)
+	assert.Equal(t, "sql.connector.connect", recorder.Ended()[0].Name())
+
+	name, err := Register("fake-connector", WithTracerProvider(recorder.TracerProvider()), WithDBSystem("mysql"))
+	require.NoError(t, err)
+	registeredDB, err := sql.Open(name, "dsn")
+	require.NoError(t, err)
+	defer registeredDB.Close()
+	_, err = registeredDB.Exec("DELETE FROM users WHERE id = 8")
+	require.NoError(t, err)
+	assert.Len(t, recorder.Ended(), 4)
+
+	_, err = sql.Open(name, "")
+	assert.EqualError(t, err, "fake: empty data source name")
+}
@@ -0,0 +1,125 @@
+// Package logging extracts the fields correlating logs with the span active
+// in a context and its Coralogix transactions. The slogcorrelation,
+// zapcorrelation and logruscorrelation packages add them to the records of
+// their logging library.
+package logging
+
+import (
+	"context"
+	"fmt"
+
+	"go.opentelemetry.io/otel/attribute"
+	traceCore "go.opentelemetry.io/otel/trace"
+
+	"github.com/coralogix/coralogix-opentelemetry-go/sampler"
+)
+
+const (
+	TraceIDKey                = "trace_id"
+	SpanIDKey                 = "span_id"
+	TransactionKey            = sampler.TransactionIdentifier
+	DistributedTransactionKey = sampler.DistributedTransactionIdentifier
+
+	// LogEventName is the name of the span events logs are recorded as.
+	LogEventName         = "log"
+	LogSeverityAttribute = "log.severity"
+	LogMessageAttribute  = "log.message"
+)
+
+// Fields are the correlation fields of a span.
+type Fields struct {
+	TraceID string
+	SpanID  string
+	// Transaction and DistributedTransaction are read from the tracestate of
+	// the span, where the CoralogixSampler records them, and are empty for
+	// spans it did not sample.
+	Transaction            string
+	DistributedTransaction string
+}
+
+// FieldsFromContext returns the correlation fields of the span active in ctx,
+// and false if there is none.
+func FieldsFromContext(ctx context.Context) (Fields, bool) {
+	if ctx == nil {
+		return Fields{}, false
+	}
+	spanContext := traceCore.SpanContextFromContext(ctx)
+	if !spanContext.IsValid() {
+		return Fields{}, false
+	}
+	traceState := spanContext.TraceState()
+	return Fields{
+		TraceID:                spanContext.TraceID().String(),
+		SpanID:                 spanContext.SpanID().String(),
+		Transaction:            traceState.Get(sampler.TransactionIdentifierTraceState),
+		DistributedTransaction: traceState.Get(sampler.DistributedTransactionIdentifierTraceState),
+	}, true
+}
+
+// Each calls fn with the key and the value of every field, in a stable order,
+// skipping the empty ones.
+func (f Fields) Each(fn func(key string, value string)) {
+	for _, field := range [...]struct{ key, value string }{
+		{TraceIDKey, f.TraceID},
+		{SpanIDKey, f.SpanID},
+		{TransactionKey, f.Transaction},
+		{DistributedTransactionKey, f.DistributedTransaction},
+	} {
+		if field.value != "" {
+			fn(field.key, field.value)
+		}
+	}
+}
+
+// AddLogEvent records a log as an event of the span active in ctx, if it is
+// recording, with its severity, its message and the attributes of its fields.
+func AddLogEvent(ctx context.Context, severity string, message string, attributes ...attribute.KeyValue) {
+	if ctx == nil {
+		return
+	}
+	span := traceCore.SpanFromContext(ctx)
+	if !span.IsRecording() {
+		return
+	}
+	attributes = append([]attribute.KeyValue{
+		attribute.String(LogSeverityAttribute, severity),
+		attribute.String(LogMessageAttribute, message),
+	}, attributes...)
+	span.AddEvent(LogEventName, traceCore.WithAttributes(attributes...))
+}
+
+// Attribute converts the value of a log field to a span attribute, keeping
+// the type of booleans, integers, floats and strings.
+func Attribute(key string, value any) attribute.KeyValue {
+	switch v := value.(type) {
+	case bool:
+		return attribute.Bool(key, v)
+	case int:
+		return attribute.Int(key, v)
+	case int8:
+		return attribute.Int64(key, int64(v))
+	case int16:
+		return attribute.Int64(key, int64(v))
+	case int32:
+		return attribute.Int64(key, int64(v))
+	case int64:
+		return attribute.Int64(key, v)
+	case uint8:
+		return attribute.Int64(key, int64(v))
+	case uint16:
+		return attribute.Int64(key, int64(v))
+	case uint32:
+		return attribute.Int64(key, int64(v))
+	case float32:
+		return attribute.Float64(key, float64(v))
+	case float64:
+		return attribute.Float64(key, v)
+	case string:
+		return attribute.String(key, v)
+	case error:
+		return attribute.String(key, v.Error())
+	case fmt.Stringer:
+		return attribute.String(key, v.String())
+	}
+	return attribute.String(key, fmt.Sprint(value))
+}
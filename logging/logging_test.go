@@ -0,0 +1,78 @@
+package logging
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	traceSdk "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	traceCore "go.opentelemetry.io/otel/trace"
+
+	"github.com/coralogix/coralogix-opentelemetry-go/sampler"
+)
+
+func TestFieldsFromContext(t *testing.T) {
+	provider := traceSdk.NewTracerProvider(traceSdk.WithSampler(sampler.NewCoralogixSampler(traceSdk.AlwaysSample())))
+	ctx, span := provider.Tracer("test").Start(context.Background(), "GET /users/{id}", traceCore.WithSpanKind(traceCore.SpanKindServer))
+	defer span.End()
+
+	fields, ok := FieldsFromContext(ctx)
+	assert.True(t, ok)
+	assert.Equal(t, Fields{
+		TraceID:                span.SpanContext().TraceID().String(),
+		SpanID:                 span.SpanContext().SpanID().String(),
+		Transaction:            "GET /users/{id}",
+		DistributedTransaction: "GET /users/{id}",
+	}, fields)
+
+	var keys []string
+	fields.Each(func(key string, value string) { keys = append(keys, key) })
+	assert.Equal(t, []string{TraceIDKey, SpanIDKey, TransactionKey, DistributedTransactionKey}, keys)
+}
+
+func TestFieldsFromContext_WithoutCoralogixSampler(t *testing.T) {
+	ctx, span := traceSdk.NewTracerProvider().Tracer("test").Start(context.Background(), "span")
+	defer span.End()
+
+	fields, ok := FieldsFromContext(ctx)
+	assert.True(t, ok)
+	var keys []string
+	fields.Each(func(key string, value string) { keys = append(keys, key) })
+	assert.Equal(t, []string{TraceIDKey, SpanIDKey}, keys)
+}
+
+func TestFieldsFromContext_WithoutSpan(t *testing.T) {
+	_, ok := FieldsFromContext(context.Background())
+	assert.False(t, ok)
+}
+
+func TestAddLogEvent(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := traceSdk.NewTracerProvider(traceSdk.WithSpanProcessor(recorder))
+	ctx, span := provider.Tracer("test").Start(context.Background(), "span")
+
+	AddLogEvent(ctx, "ERROR", "payment declined", Attribute("order", 7), Attribute("err", errors.New("card expired")))
+	AddLogEvent(context.Background(), "ERROR", "not recorded")
+	span.End()
+
+	events := recorder.Ended()[0].Events()
+	require.Len(t, events, 1)
+	assert.Equal(t, LogEventName, events[0].Name)
+	assert.Equal(t, []attribute.KeyValue{
+		attribute.String(LogSeverityAttribute, "ERROR"),
+		attribute.String(LogMessageAttribute, "payment declined"),
+		attribute.Int("order", 7),
+		attribute.String("err", "card expired"),
+	}, events[0].Attributes)
+}
+
+func TestAttribute(t *testing.T) {
+	assert.Equal(t, attribute.Bool("k", true), Attribute("k", true))
+	assert.Equal(t, attribute.Int64("k", 3), Attribute("k", uint16(3)))
+	assert.Equal(t, attribute.Float64("k", 0.5), Attribute("k", float32(0.5)))
+	assert.Equal(t, attribute.String("k", "[1 2]"), Attribute("k", []int{1, 2}))
+}
@@ -0,0 +1,75 @@
+// Package logruscorrelation correlates github.com/sirupsen/logrus logs with
+// traces and Coralogix transactions.
+package logruscorrelation
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/attribute"
+
+	"github.com/coralogix/coralogix-opentelemetry-go/logging"
+)
+
+type Option func(*Hook)
+
+// WithErrorEvents also records logs of error level and above as events of
+// the span active in their context.
+func WithErrorEvents() Option {
+	return func(h *Hook) {
+		h.errorEvents = true
+	}
+}
+
+// Hook adds the trace_id, span_id, cgx.transaction and
+// cgx.transaction.distributed fields of the span active in the context of an
+// entry, given with WithContext, to the entry.
+type Hook struct {
+	errorEvents bool
+}
+
+// NewHook returns a hook to add to a logger:
+//
+//	logger.AddHook(logruscorrelation.NewHook())
+//	logger.WithContext(ctx).Info("order saved")
+func NewHook(options ...Option) *Hook {
+	h := &Hook{}
+	for _, option := range options {
+		option(h)
+	}
+	return h
+}
+
+func (h *Hook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+func (h *Hook) Fire(entry *logrus.Entry) error {
+	correlation, ok := logging.FieldsFromContext(entry.Context)
+	if !ok {
+		return nil
+	}
+	if h.errorEvents && entry.Level <= logrus.ErrorLevel {
+		logging.AddLogEvent(entry.Context, strings.ToUpper(entry.Level.String()), entry.Message, eventAttributes(entry.Data)...)
+	}
+	correlation.Each(func(key string, value string) {
+		entry.Data[key] = value
+	})
+	return nil
+}
+
+// eventAttributes converts the fields of an entry to attributes, sorted by
+// key.
+func eventAttributes(data logrus.Fields) []attribute.KeyValue {
+	keys := make([]string, 0, len(data))
+	for key := range data {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	attributes := make([]attribute.KeyValue, 0, len(keys))
+	for _, key := range keys {
+		attributes = append(attributes, logging.Attribute(key, data[key]))
+	}
+	return attributes
+}
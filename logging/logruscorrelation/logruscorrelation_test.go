@@ -0,0 +1,87 @@
+package logruscorrelation
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	traceSdk "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	traceCore "go.opentelemetry.io/otel/trace"
+
+	"github.com/coralogix/coralogix-opentelemetry-go/logging"
+	"github.com/coralogix/coralogix-opentelemetry-go/sampler"
+)
+
+func newTestLogger(options ...Option) (*logrus.Logger, *bytes.Buffer) {
+	buffer := &bytes.Buffer{}
+	logger := logrus.New()
+	logger.SetOutput(buffer)
+	logger.SetFormatter(&logrus.JSONFormatter{})
+	logger.AddHook(NewHook(options...))
+	return logger, buffer
+}
+
+func decode(t *testing.T, buffer *bytes.Buffer) map[string]any {
+	record := map[string]any{}
+	require.NoError(t, json.Unmarshal(buffer.Bytes(), &record))
+	buffer.Reset()
+	return record
+}
+
+func TestHook(t *testing.T) {
+	logger, buffer := newTestLogger()
+	tracer := traceSdk.NewTracerProvider(traceSdk.WithSampler(sampler.NewCoralogixSampler(traceSdk.AlwaysSample()))).Tracer("test")
+	ctx, server := tracer.Start(context.Background(), "POST /orders", traceCore.WithSpanKind(traceCore.SpanKindServer))
+	defer server.End()
+	ctx, span := tracer.Start(ctx, "save order")
+	defer span.End()
+
+	logger.WithContext(ctx).WithField("order", 7).Info("order saved")
+
+	record := decode(t, buffer)
+	assert.Equal(t, "order saved", record["msg"])
+	assert.Equal(t, float64(7), record["order"])
+	assert.Equal(t, span.SpanContext().TraceID().String(), record["trace_id"])
+	assert.Equal(t, span.SpanContext().SpanID().String(), record["span_id"])
+	assert.Equal(t, "POST /orders", record["cgx.transaction"])
+	assert.Equal(t, "POST /orders", record["cgx.transaction.distributed"])
+}
+
+func TestHook_WithoutContext(t *testing.T) {
+	logger, buffer := newTestLogger()
+
+	logger.WithContext(context.Background()).Info("starting")
+	assert.NotContains(t, decode(t, buffer), "trace_id")
+
+	logger.Info("started")
+	assert.NotContains(t, decode(t, buffer), "trace_id")
+}
+
+func TestHook_ErrorEvents(t *testing.T) {
+	logger, buffer := newTestLogger(WithErrorEvents())
+	recorder := tracetest.NewSpanRecorder()
+	ctx, span := traceSdk.NewTracerProvider(traceSdk.WithSpanProcessor(recorder)).Tracer("test").Start(context.Background(), "span")
+
+	logger.WithContext(ctx).Warn("retrying")
+	logger.WithContext(ctx).WithFields(logrus.Fields{"order": 7, "reason": "card expired"}).WithError(errors.New("declined")).Error("payment failed")
+	span.End()
+
+	assert.Contains(t, buffer.String(), span.SpanContext().SpanID().String())
+	events := recorder.Ended()[0].Events()
+	require.Len(t, events, 1)
+	assert.Equal(t, logging.LogEventName, events[0].Name)
+	assert.Equal(t, []attribute.KeyValue{
+		attribute.String(logging.LogSeverityAttribute, "ERROR"),
+		attribute.String(logging.LogMessageAttribute, "payment failed"),
+		attribute.String("error", "declined"),
+		attribute.Int("order", 7),
+		attribute.String("reason", "card expired"),
+	}, events[0].Attributes)
+}
@@ -0,0 +1,53 @@
+// Package slogcorrelation correlates log/slog records with traces and
+// Coralogix transactions.
+package slogcorrelation
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/coralogix/coralogix-opentelemetry-go/logging"
+)
+
+// Handler adds the trace_id, span_id, cgx.transaction and
+// cgx.transaction.distributed fields of the span active in the context of a
+// record to the record, before passing it to the wrapped handler. Records
+// logged without context, or outside of any span, are passed unchanged.
+//
+// Like any attribute added by a handler, the fields belong to the groups
+// opened with WithGroup.
+type Handler struct {
+	next slog.Handler
+}
+
+var _ slog.Handler = (*Handler)(nil)
+
+func NewHandler(next slog.Handler) *Handler {
+	if next == nil {
+		panic("handler is null")
+	}
+	return &Handler{next: next}
+}
+
+func (h *Handler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+func (h *Handler) Handle(ctx context.Context, record slog.Record) error {
+	if fields, ok := logging.FieldsFromContext(ctx); ok {
+		// the record may be shared with other handlers
+		record = record.Clone()
+		fields.Each(func(key string, value string) {
+			record.AddAttrs(slog.String(key, value))
+		})
+	}
+	return h.next.Handle(ctx, record)
+}
+
+func (h *Handler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &Handler{next: h.next.WithAttrs(attrs)}
+}
+
+func (h *Handler) WithGroup(name string) slog.Handler {
+	return &Handler{next: h.next.WithGroup(name)}
+}
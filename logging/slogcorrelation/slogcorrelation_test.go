@@ -0,0 +1,73 @@
+package slogcorrelation
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	traceSdk "go.opentelemetry.io/otel/sdk/trace"
+	traceCore "go.opentelemetry.io/otel/trace"
+
+	"github.com/coralogix/coralogix-opentelemetry-go/sampler"
+)
+
+func newTestLogger() (*slog.Logger, *bytes.Buffer) {
+	buffer := &bytes.Buffer{}
+	return slog.New(NewHandler(slog.NewJSONHandler(buffer, nil))), buffer
+}
+
+func decode(t *testing.T, buffer *bytes.Buffer) map[string]any {
+	record := map[string]any{}
+	require.NoError(t, json.Unmarshal(buffer.Bytes(), &record))
+	buffer.Reset()
+	return record
+}
+
+func TestHandler(t *testing.T) {
+	logger, buffer := newTestLogger()
+	tracer := traceSdk.NewTracerProvider(traceSdk.WithSampler(sampler.NewCoralogixSampler(traceSdk.AlwaysSample()))).Tracer("test")
+	ctx, server := tracer.Start(context.Background(), "POST /orders", traceCore.WithSpanKind(traceCore.SpanKindServer))
+	defer server.End()
+	ctx, span := tracer.Start(ctx, "save order")
+	defer span.End()
+
+	logger.With("order", 7).InfoContext(ctx, "order saved")
+
+	record := decode(t, buffer)
+	assert.Equal(t, "order saved", record["msg"])
+	assert.Equal(t, float64(7), record["order"])
+	assert.Equal(t, span.SpanContext().TraceID().String(), record["trace_id"])
+	assert.Equal(t, span.SpanContext().SpanID().String(), record["span_id"])
+	assert.Equal(t, "POST /orders", record["cgx.transaction"])
+	assert.Equal(t, "POST /orders", record["cgx.transaction.distributed"])
+}
+
+func TestHandler_WithoutSpan(t *testing.T) {
+	logger, buffer := newTestLogger()
+
+	logger.InfoContext(context.Background(), "starting")
+	logger.Info("started")
+
+	for i := 0; i < 2; i++ {
+		line, err := buffer.ReadBytes('\n')
+		require.NoError(t, err)
+		record := map[string]any{}
+		require.NoError(t, json.Unmarshal(line, &record))
+		assert.NotContains(t, record, "trace_id")
+	}
+}
+
+func TestHandler_Group(t *testing.T) {
+	logger, buffer := newTestLogger()
+	ctx, span := traceSdk.NewTracerProvider().Tracer("test").Start(context.Background(), "span")
+	defer span.End()
+
+	logger.WithGroup("request").InfoContext(ctx, "handled", "status", 200)
+
+	record := decode(t, buffer)
+	assert.Equal(t, map[string]any{"status": float64(200), "trace_id": span.SpanContext().TraceID().String(), "span_id": span.SpanContext().SpanID().String()}, record["request"])
+}
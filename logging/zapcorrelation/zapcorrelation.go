@@ -0,0 +1,144 @@
+// Package zapcorrelation correlates go.uber.org/zap logs with traces and
+// Coralogix transactions.
+package zapcorrelation
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+
+	"github.com/coralogix/coralogix-opentelemetry-go/logging"
+)
+
+// Context returns a field carrying ctx, which adds the correlation fields of
+// the span active in ctx to the log. The field encodes them itself, so they
+// are written by whichever cores accept the log, with or without NewCore.
+func Context(ctx context.Context) zap.Field {
+	return zap.Inline(contextFields{ctx: ctx})
+}
+
+// contextFields encodes the correlation fields of the span active in ctx.
+type contextFields struct {
+	ctx context.Context
+}
+
+func (f contextFields) MarshalLogObject(encoder zapcore.ObjectEncoder) error {
+	if correlation, ok := logging.FieldsFromContext(f.ctx); ok {
+		correlation.Each(func(key string, value string) {
+			encoder.AddString(key, value)
+		})
+	}
+	return nil
+}
+
+type Option func(*core)
+
+// WithErrorEvents records logs of error level and above as events of the span
+// active in their context.
+func WithErrorEvents() Option {
+	return func(c *core) {
+		c.errorEvents = true
+	}
+}
+
+// core records the logs given a Context field, per log or with With, as
+// events of their span. It leaves the decision to log an entry to the core it
+// wraps, so that the levels of a tee or a sampler are honored.
+type core struct {
+	zapcore.Core
+	// ctx is the context bound to the core with With, if any.
+	ctx         context.Context
+	errorEvents bool
+}
+
+// NewCore wraps next, e.g. with zap.WrapCore:
+//
+//	logger = logger.WithOptions(zap.WrapCore(func(core zapcore.Core) zapcore.Core {
+//		return zapcorrelation.NewCore(core, zapcorrelation.WithErrorEvents())
+//	}))
+//	logger.Error("payment declined", zapcorrelation.Context(ctx))
+func NewCore(next zapcore.Core, options ...Option) zapcore.Core {
+	c := &core{Core: next}
+	for _, option := range options {
+		option(c)
+	}
+	return c
+}
+
+func (c *core) With(fields []zapcore.Field) zapcore.Core {
+	ctx := extractContext(fields)
+	if ctx == nil {
+		ctx = c.ctx
+	}
+	return &core{Core: c.Core.With(fields), ctx: ctx, errorEvents: c.errorEvents}
+}
+
+func (c *core) Check(entry zapcore.Entry, checked *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	checked = c.Core.Check(entry, checked)
+	if checked != nil && c.errorEvents && entry.Level >= zapcore.ErrorLevel {
+		checked = checked.AddCore(entry, eventCore{ctx: c.ctx})
+	}
+	return checked
+}
+
+// eventCore records the entries it writes as span events, without writing
+// them anywhere else.
+type eventCore struct {
+	ctx context.Context
+}
+
+func (c eventCore) Enabled(zapcore.Level) bool {
+	return true
+}
+
+func (c eventCore) With([]zapcore.Field) zapcore.Core {
+	return c
+}
+
+func (c eventCore) Check(entry zapcore.Entry, checked *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	return checked.AddCore(entry, c)
+}
+
+func (c eventCore) Write(entry zapcore.Entry, fields []zapcore.Field) error {
+	ctx := extractContext(fields)
+	if ctx == nil {
+		ctx = c.ctx
+	}
+	logging.AddLogEvent(ctx, entry.Level.CapitalString(), entry.Message, eventAttributes(fields)...)
+	return nil
+}
+
+func (c eventCore) Sync() error {
+	return nil
+}
+
+// extractContext returns the context carried by the Context field of fields,
+// if any.
+func extractContext(fields []zapcore.Field) context.Context {
+	for _, field := range fields {
+		if f, ok := field.Interface.(contextFields); ok && field.Type == zapcore.InlineMarshalerType {
+			return f.ctx
+		}
+	}
+	return nil
+}
+
+// eventAttributes converts the fields of a log to attributes, but the
+// correlation ones, which the span already has.
+func eventAttributes(fields []zapcore.Field) []attribute.KeyValue {
+	encoder := zapcore.NewMapObjectEncoder()
+	for _, field := range fields {
+		field.AddTo(encoder)
+	}
+	attributes := make([]attribute.KeyValue, 0, len(encoder.Fields))
+	for _, field := range fields {
+		value, ok := encoder.Fields[field.Key]
+		if !ok {
+			continue
+		}
+		attributes = append(attributes, logging.Attribute(field.Key, value))
+	}
+	return attributes
+}
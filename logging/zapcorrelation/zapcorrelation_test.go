@@ -0,0 +1,126 @@
+package zapcorrelation
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	traceSdk "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	traceCore "go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+
+	"github.com/coralogix/coralogix-opentelemetry-go/logging"
+	"github.com/coralogix/coralogix-opentelemetry-go/sampler"
+)
+
+func newTestLogger(options ...Option) (*zap.Logger, *bytes.Buffer) {
+	buffer := &bytes.Buffer{}
+	next := zapcore.NewCore(zapcore.NewJSONEncoder(zap.NewProductionEncoderConfig()), zapcore.AddSync(buffer), zapcore.DebugLevel)
+	return zap.New(NewCore(next, options...)), buffer
+}
+
+func decode(t *testing.T, buffer *bytes.Buffer) map[string]any {
+	record := map[string]any{}
+	require.NoError(t, json.Unmarshal(buffer.Bytes(), &record))
+	buffer.Reset()
+	return record
+}
+
+func TestCore(t *testing.T) {
+	logger, buffer := newTestLogger()
+	tracer := traceSdk.NewTracerProvider(traceSdk.WithSampler(sampler.NewCoralogixSampler(traceSdk.AlwaysSample()))).Tracer("test")
+	ctx, server := tracer.Start(context.Background(), "POST /orders", traceCore.WithSpanKind(traceCore.SpanKindServer))
+	defer server.End()
+	ctx, span := tracer.Start(ctx, "save order")
+	defer span.End()
+
+	logger.Info("order saved", Context(ctx), zap.Int("order", 7))
+
+	record := decode(t, buffer)
+	assert.Equal(t, "order saved", record["msg"])
+	assert.Equal(t, float64(7), record["order"])
+	assert.Equal(t, span.SpanContext().TraceID().String(), record["trace_id"])
+	assert.Equal(t, span.SpanContext().SpanID().String(), record["span_id"])
+	assert.Equal(t, "POST /orders", record["cgx.transaction"])
+	assert.Equal(t, "POST /orders", record["cgx.transaction.distributed"])
+
+	logger.With(Context(ctx)).With(zap.String("user", "ada")).Info("order shipped")
+
+	record = decode(t, buffer)
+	assert.Equal(t, "ada", record["user"])
+	assert.Equal(t, span.SpanContext().SpanID().String(), record["span_id"])
+}
+
+func TestCore_WithoutContext(t *testing.T) {
+	logger, buffer := newTestLogger()
+
+	logger.Info("started", Context(context.Background()))
+	assert.NotContains(t, decode(t, buffer), "trace_id")
+
+	logger.Info("started")
+	assert.NotContains(t, decode(t, buffer), "trace_id")
+}
+
+func TestCore_ErrorEvents(t *testing.T) {
+	logger, buffer := newTestLogger(WithErrorEvents())
+	recorder := tracetest.NewSpanRecorder()
+	ctx, span := traceSdk.NewTracerProvider(traceSdk.WithSpanProcessor(recorder)).Tracer("test").Start(context.Background(), "span")
+
+	logger.Warn("retrying", Context(ctx))
+	logger.Error("payment declined", Context(ctx), zap.Int("order", 7), zap.String("reason", "card expired"))
+	span.End()
+
+	assert.Contains(t, buffer.String(), span.SpanContext().SpanID().String())
+	events := recorder.Ended()[0].Events()
+	require.Len(t, events, 1)
+	assert.Equal(t, logging.LogEventName, events[0].Name)
+	assert.Equal(t, []attribute.KeyValue{
+		attribute.String(logging.LogSeverityAttribute, "ERROR"),
+		attribute.String(logging.LogMessageAttribute, "payment declined"),
+		attribute.Int64("order", 7),
+		attribute.String("reason", "card expired"),
+	}, events[0].Attributes)
+}
+
+func TestCore_Tee(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	ctx, span := traceSdk.NewTracerProvider(traceSdk.WithSpanProcessor(recorder)).Tracer("test").Start(context.Background(), "span")
+	info, infoLogs := observer.New(zapcore.InfoLevel)
+	errorCore, errorLogs := observer.New(zapcore.ErrorLevel)
+	logger := zap.New(NewCore(zapcore.NewTee(info, errorCore), WithErrorEvents()))
+
+	logger.Info("order saved", Context(ctx))
+	logger.Error("payment declined", Context(ctx))
+	span.End()
+
+	assert.Equal(t, 2, infoLogs.Len())
+	require.Equal(t, 1, errorLogs.Len())
+	entry := errorLogs.All()[0]
+	assert.Equal(t, "payment declined", entry.Message)
+	assert.Equal(t, span.SpanContext().TraceID().String(), entry.ContextMap()["trace_id"])
+	assert.Len(t, recorder.Ended()[0].Events(), 1)
+}
+
+func TestCore_Sampler(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	ctx, span := traceSdk.NewTracerProvider(traceSdk.WithSpanProcessor(recorder)).Tracer("test").Start(context.Background(), "span")
+	observed, logs := observer.New(zapcore.DebugLevel)
+	logger := zap.New(NewCore(zapcore.NewSamplerWithOptions(observed, time.Minute, 1, 0), WithErrorEvents()))
+
+	for i := 0; i < 5; i++ {
+		logger.Error("payment declined", Context(ctx))
+	}
+	span.End()
+
+	require.Equal(t, 1, logs.Len())
+	assert.Equal(t, span.SpanContext().SpanID().String(), logs.All()[0].ContextMap()["span_id"])
+	assert.Len(t, recorder.Ended()[0].Events(), 1)
+}
@@ -0,0 +1,185 @@
+// Package elasticsearchparser normalizes Elasticsearch request bodies recorded
+// in db.statement.
+package elasticsearchparser
+
+import (
+	"context"
+
+	"github.com/coralogix/coralogix-opentelemetry-go/processor/internal/jsonredact"
+)
+
+// requestKeys are the members of a search body whose whole value names
+// fields or sorts on them. They are data in other bodies, such as documents.
+var requestKeys = map[string]bool{
+	"_source":         true,
+	"docvalue_fields": true,
+	"fields":          true,
+	"sort":            true,
+	"stored_fields":   true,
+}
+
+// searchKeys are the members a search body starts with, as opposed to the
+// documents of a bulk request, whose members are all redacted.
+var searchKeys = map[string]bool{
+	"aggregations": true,
+	"aggs":         true,
+	"post_filter":  true,
+	"query":        true,
+}
+
+// bulkActions are the actions of the lines of a _bulk body naming the index of
+// the document that follows.
+var bulkActions = map[string]bool{
+	"create": true,
+	"delete": true,
+	"index":  true,
+	"update": true,
+}
+
+// fieldQueryOptions are the options of the queries whose members are field
+// names, as in {"range":{"date":{"format":"yyyy-MM-dd"}}}.
+var fieldQueryOptions = map[string]map[string]bool{
+	"fuzzy":               {"rewrite": true},
+	"match":               {"analyzer": true, "operator": true},
+	"match_bool_prefix":   {"analyzer": true, "operator": true},
+	"match_phrase":        {"analyzer": true},
+	"match_phrase_prefix": {"analyzer": true},
+	"prefix":              {"rewrite": true},
+	"range":               {"format": true, "relation": true, "time_zone": true},
+	"regexp":              {"rewrite": true},
+	"term":                {},
+	"terms":               {},
+	"wildcard":            {"rewrite": true},
+}
+
+// queryOptions are the options of the queries whose members are options, as
+// in {"multi_match":{"fields":["title"]}}.
+var queryOptions = map[string]map[string]bool{
+	"exists":              {"field": true},
+	"has_child":           {"type": true},
+	"has_parent":          {"parent_type": true},
+	"multi_match":         {"analyzer": true, "fields": true, "operator": true, "type": true},
+	"nested":              {"path": true, "score_mode": true},
+	"query_string":        {"analyzer": true, "default_field": true, "default_operator": true, "fields": true},
+	"simple_query_string": {"analyzer": true, "default_operator": true, "fields": true},
+}
+
+// aggregationOptions are the options of aggregations, as in
+// {"aggs":{"by_day":{"date_histogram":{"field":"@timestamp"}}}}.
+var aggregationOptions = map[string]bool{
+	"calendar_interval": true,
+	"field":             true,
+	"fields":            true,
+	"fixed_interval":    true,
+	"format":            true,
+	"interval":          true,
+	"order":             true,
+	"path":              true,
+	"time_zone":         true,
+}
+
+// ElasticsearchParse replaces the values of an Elasticsearch request body with
+// '?', keeping the query structure, field names and the values of the options
+// that name fields or formats, such as "sort" or the "field" of an
+// aggregation, so that
+//
+//	{"query":{"match":{"name":"alice"}},"sort":["age"],"size":10}
+//
+// is normalized to
+//
+//	{"query":{"match":{"name":"?"}},"sort":["age"],"size":"?"}
+//
+// Newline delimited bodies, as sent to _bulk and _msearch, are normalized
+// document by document and repeated documents are dropped, so that bulk
+// requests of any size are rendered the same way. The documents of a _bulk
+// body are redacted whole.
+func ElasticsearchParse(dbStatementStr *string) (string, error) {
+	nodes, err := jsonredact.Parse(*dbStatementStr)
+	if err != nil {
+		return *dbStatementStr, err
+	}
+	document := false
+	for _, node := range nodes {
+		switch {
+		case document:
+			node.Redact(isData)
+			document = false
+		case isBulkAction(node):
+			node.Redact(isStructural)
+			document = node.FirstKey() != "delete"
+		case isSearch(node):
+			node.Redact(isSearchStructural)
+		default:
+			node.Redact(isStructural)
+		}
+	}
+	return jsonredact.Join(jsonredact.Distinct(nodes)), nil
+}
+
+// isBulkAction tells whether node is the action line of a _bulk body, as
+// opposed to the header line of a _msearch body, whose index is a string.
+func isBulkAction(node *jsonredact.Node) bool {
+	action := node.FirstKey()
+	return bulkActions[action] && node.Member(action).IsObject()
+}
+
+// isSearch tells whether node is a search body.
+func isSearch(node *jsonredact.Node) bool {
+	for key := range searchKeys {
+		if node.Member(key) != nil {
+			return true
+		}
+	}
+	return false
+}
+
+func isData([]string) bool {
+	return false
+}
+
+// isSearchStructural is isStructural for search bodies, which also keeps the
+// values of requestKeys.
+func isSearchStructural(path []string) bool {
+	return len(path) > 0 && requestKeys[path[0]] || isStructural(path)
+}
+
+// isStructural tells whether the value at path is an option of the
+// Elasticsearch DSL rather than data. Option names are only recognized where
+// the DSL puts options, so that fields named "type" or "order", in queries or
+// in documents, are redacted.
+func isStructural(path []string) bool {
+	if len(path) == 0 {
+		return false
+	}
+	if len(path) == 2 && bulkActions[path[0]] && path[1] == "_index" {
+		return true
+	}
+	if !searchKeys[path[0]] {
+		return false
+	}
+	last := len(path) - 1
+	for i, key := range path {
+		if options, ok := fieldQueryOptions[key]; ok && i+2 == last && options[path[last]] {
+			return true
+		}
+		if options, ok := queryOptions[key]; ok && i+1 == last && options[path[last]] {
+			return true
+		}
+		// aggregations nest as aggs > name > type > option, order being an object
+		if (key == "aggs" || key == "aggregations") && i+3 <= last && aggregationOptions[path[i+3]] && (i+3 == last || path[i+3] == "order") {
+			return true
+		}
+	}
+	return false
+}
+
+func ElasticsearchSpanFormatter(ctx context.Context, method string, query string) string {
+	if query != "" {
+		parsed, err := ElasticsearchParse(&query)
+		if err != nil {
+			return method
+		}
+		return parsed
+	}
+	return method
+}
@@ -0,0 +1,109 @@
+package elasticsearchparser
+
+import (
+	"context"
+	"testing"
+)
+
+func TestElasticsearchParse(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+		wantErr  bool
+	}{
+		{
+			name:     "match query",
+			input:    `{"query":{"match":{"name":"alice"}},"sort":["age"],"size":10}`,
+			expected: `{"query":{"match":{"name":"?"}},"sort":["age"],"size":"?"}`,
+		},
+		{
+			name:     "bool query with terms",
+			input:    `{"query":{"bool":{"must":[{"term":{"status":"active"}},{"range":{"age":{"gte":18,"lt":65}}}],"filter":{"terms":{"id":[1,2,3]}}}}}`,
+			expected: `{"query":{"bool":{"must":[{"term":{"status":"?"}},{"range":{"age":{"gte":"?","lt":"?"}}}],"filter":{"terms":{"id":["?"]}}}}}`,
+		},
+		{
+			name:     "aggregations keep fields",
+			input:    `{"size":0,"aggs":{"by_day":{"date_histogram":{"field":"@timestamp","calendar_interval":"day"}},"top":{"terms":{"field":"user","size":5,"order":{"_count":"desc"}}}}}`,
+			expected: `{"size":"?","aggs":{"by_day":{"date_histogram":{"field":"@timestamp","calendar_interval":"day"}},"top":{"terms":{"field":"user","size":"?","order":{"_count":"desc"}}}}}`,
+		},
+		{
+			name:     "multi match",
+			input:    `{"query":{"multi_match":{"query":"john smith","fields":["first","last"],"operator":"and"}},"_source":["first"]}`,
+			expected: `{"query":{"multi_match":{"query":"?","fields":["first","last"],"operator":"and"}},"_source":["first"]}`,
+		},
+		{
+			name:     "bulk body",
+			input:    "{\"index\":{\"_index\":\"logs\",\"_id\":\"1\"}}\n{\"msg\":\"a\"}\n{\"index\":{\"_index\":\"logs\",\"_id\":\"2\"}}\n{\"msg\":\"b\"}\n",
+			expected: "{\"index\":{\"_index\":\"logs\",\"_id\":\"?\"}}\n{\"msg\":\"?\"}",
+		},
+		{
+			name:     "term on a field named like an option",
+			input:    `{"query":{"term":{"type":"premium-customer-ssn-123"}}}`,
+			expected: `{"query":{"term":{"type":"?"}}}`,
+		},
+		{
+			name:     "term on a field named order",
+			input:    `{"query":{"bool":{"filter":[{"term":{"order":"ORD-12345"}}]}},"post_filter":{"term":{"order":{"value":"ORD-1"}}}}`,
+			expected: `{"query":{"bool":{"filter":[{"term":{"order":"?"}}]}},"post_filter":{"term":{"order":{"value":"?"}}}}`,
+		},
+		{
+			name:     "bulk document with option named fields",
+			input:    "{\"index\":{\"_index\":\"payments\"}}\n{\"format\":\"card 4111111111111111\",\"order\":{\"path\":\"x\"},\"term\":{\"type\":\"y\"}}\n",
+			expected: "{\"index\":{\"_index\":\"payments\"}}\n{\"format\":\"?\",\"order\":{\"path\":\"?\"},\"term\":{\"type\":\"?\"}}",
+		},
+		{
+			name:     "document with request key names",
+			input:    `{"name":"alice","fields":"ssn-123-45-6789","sort":"alice@example.com"}`,
+			expected: `{"name":"?","fields":"?","sort":"?"}`,
+		},
+		{
+			name:     "bulk document with request key names",
+			input:    "{\"index\":{}}\n{\"query\":\"x\",\"_source\":\"ssn-123-45-6789\",\"sort\":[\"alice@example.com\"]}\n{\"delete\":{\"_index\":\"logs\",\"_id\":\"3\"}}\n{\"query\":{\"match_all\":{}},\"sort\":[\"age\"]}\n",
+			expected: "{\"index\":{}}\n{\"query\":\"?\",\"_source\":\"?\",\"sort\":[\"?\"]}\n{\"delete\":{\"_index\":\"logs\",\"_id\":\"?\"}}\n{\"query\":{\"match_all\":{}},\"sort\":[\"age\"]}",
+		},
+		{
+			name:     "msearch body",
+			input:    "{\"index\":\"logs\"}\n{\"query\":{\"match\":{\"msg\":\"boom\"}},\"sort\":[\"@timestamp\"]}\n",
+			expected: "{\"index\":\"?\"}\n{\"query\":{\"match\":{\"msg\":\"?\"}},\"sort\":[\"@timestamp\"]}",
+		},
+		{
+			name:     "query options",
+			input:    `{"query":{"bool":{"must":[{"range":{"date":{"gte":"2024-01-01","format":"yyyy-MM-dd"}}},{"match":{"title":{"query":"secret","operator":"and"}}},{"exists":{"field":"email"}},{"nested":{"path":"items","query":{"term":{"items.format":"pdf"}}}}]}}}`,
+			expected: `{"query":{"bool":{"must":[{"range":{"date":{"gte":"?","format":"yyyy-MM-dd"}}},{"match":{"title":{"query":"?","operator":"and"}}},{"exists":{"field":"email"}},{"nested":{"path":"items","query":{"term":{"items.format":"?"}}}}]}}}`,
+		},
+		{
+			name:     "sub aggregations and filters",
+			input:    `{"aggs":{"order":{"filter":{"term":{"type":"vip"}},"aggs":{"by_type":{"terms":{"field":"type","order":{"_key":"asc"}}}}}}}`,
+			expected: `{"aggs":{"order":{"filter":{"term":{"type":"?"}},"aggs":{"by_type":{"terms":{"field":"type","order":{"_key":"asc"}}}}}}}`,
+		},
+		{
+			name:    "invalid body",
+			input:   `{"query":`,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ElasticsearchParse(&tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ElasticsearchParse() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !tt.wantErr && got != tt.expected {
+				t.Errorf("ElasticsearchParse() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestElasticsearchSpanFormatter(t *testing.T) {
+	if got := ElasticsearchSpanFormatter(context.Background(), "search", "{"); got != "search" {
+		t.Errorf("ElasticsearchSpanFormatter() = %v, want search", got)
+	}
+	got := ElasticsearchSpanFormatter(context.Background(), "search", `{"query":{"term":{"id":5}}}`)
+	if expected := `{"query":{"term":{"id":"?"}}}`; got != expected {
+		t.Errorf("ElasticsearchSpanFormatter() = %v, want %v", got, expected)
+	}
+}
@@ -0,0 +1,211 @@
+// Package jsonredact rewrites JSON documents carried in db.statement, such as
+// MongoDB commands and Elasticsearch query bodies, without their values.
+package jsonredact
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"strings"
+)
+
+// Placeholder replaces redacted scalar values.
+const Placeholder = `"?"`
+
+type kind int
+
+const (
+	scalarKind kind = iota
+	objectKind
+	arrayKind
+)
+
+// Node is a JSON value keeping the order of object members.
+type Node struct {
+	kind   kind
+	scalar string
+	keys   []string
+	values []*Node
+}
+
+// Parse decodes the sequence of JSON documents in data, separated by whitespace
+// or newlines as in Elasticsearch _bulk and _msearch bodies.
+func Parse(data string) ([]*Node, error) {
+	decoder := json.NewDecoder(strings.NewReader(data))
+	decoder.UseNumber()
+	var nodes []*Node
+	for {
+		node, err := parseValue(decoder)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		nodes = append(nodes, node)
+	}
+	if len(nodes) == 0 {
+		return nil, errors.New("jsonredact: empty document")
+	}
+	return nodes, nil
+}
+
+func parseValue(decoder *json.Decoder) (*Node, error) {
+	t, err := decoder.Token()
+	if err != nil {
+		return nil, err
+	}
+	switch t {
+	case json.Delim('{'):
+		node := &Node{kind: objectKind}
+		for decoder.More() {
+			key, err := decoder.Token()
+			if err != nil {
+				return nil, err
+			}
+			value, err := parseValue(decoder)
+			if err != nil {
+				return nil, unexpectedEOF(err)
+			}
+			node.keys = append(node.keys, key.(string))
+			node.values = append(node.values, value)
+		}
+		_, err := decoder.Token()
+		return node, unexpectedEOF(err)
+	case json.Delim('['):
+		node := &Node{kind: arrayKind}
+		for decoder.More() {
+			value, err := parseValue(decoder)
+			if err != nil {
+				return nil, unexpectedEOF(err)
+			}
+			node.values = append(node.values, value)
+		}
+		_, err := decoder.Token()
+		return node, unexpectedEOF(err)
+	case json.Delim('}'), json.Delim(']'):
+		return nil, errors.New("jsonredact: unexpected delimiter")
+	}
+	encoded, err := json.Marshal(t)
+	if err != nil {
+		return nil, err
+	}
+	return &Node{kind: scalarKind, scalar: string(encoded)}, nil
+}
+
+func unexpectedEOF(err error) error {
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
+
+// FirstKey returns the key of the first member of an object, as MongoDB
+// commands are named by it.
+func (n *Node) FirstKey() string {
+	if n.kind != objectKind || len(n.keys) == 0 {
+		return ""
+	}
+	return n.keys[0]
+}
+
+// IsObject reports whether the node is an object.
+func (n *Node) IsObject() bool {
+	return n.kind == objectKind
+}
+
+// Member returns the value of the member of an object named key, or nil.
+func (n *Node) Member(key string) *Node {
+	for i, k := range n.keys {
+		if k == key {
+			return n.values[i]
+		}
+	}
+	return nil
+}
+
+// Redact replaces every scalar value with Placeholder, unless keep reports that
+// the keys leading to it, from the root document, hold structure rather than
+// data (a collection or field name...). Arrays are collapsed to their distinct
+// elements, so that lists of values of any length are rendered the same way.
+func (n *Node) Redact(keep func(path []string) bool) {
+	n.redact(nil, keep)
+}
+
+func (n *Node) redact(path []string, keep func(path []string) bool) {
+	switch n.kind {
+	case scalarKind:
+		if n.scalar != "null" && !keep(path) {
+			n.scalar = Placeholder
+		}
+	case objectKind:
+		for i, value := range n.values {
+			value.redact(append(path[:len(path):len(path)], n.keys[i]), keep)
+		}
+	case arrayKind:
+		for _, value := range n.values {
+			value.redact(path, keep)
+		}
+		n.values = Distinct(n.values)
+	}
+}
+
+// Distinct returns nodes without the ones equal to a previous node.
+func Distinct(nodes []*Node) []*Node {
+	seen := map[string]bool{}
+	distinct := nodes[:0]
+	for _, node := range nodes {
+		encoded := node.String()
+		if seen[encoded] {
+			continue
+		}
+		seen[encoded] = true
+		distinct = append(distinct, node)
+	}
+	return distinct
+}
+
+// String returns the compact JSON encoding of the node.
+func (n *Node) String() string {
+	var b bytes.Buffer
+	n.write(&b)
+	return b.String()
+}
+
+func (n *Node) write(b *bytes.Buffer) {
+	switch n.kind {
+	case scalarKind:
+		b.WriteString(n.scalar)
+	case objectKind:
+		b.WriteByte('{')
+		for i, key := range n.keys {
+			if i > 0 {
+				b.WriteByte(',')
+			}
+			encoded, _ := json.Marshal(key)
+			b.Write(encoded)
+			b.WriteByte(':')
+			n.values[i].write(b)
+		}
+		b.WriteByte('}')
+	case arrayKind:
+		b.WriteByte('[')
+		for i, value := range n.values {
+			if i > 0 {
+				b.WriteByte(',')
+			}
+			value.write(b)
+		}
+		b.WriteByte(']')
+	}
+}
+
+// Join encodes nodes as newline separated documents.
+func Join(nodes []*Node) string {
+	encoded := make([]string, len(nodes))
+	for i, node := range nodes {
+		encoded[i] = node.String()
+	}
+	return strings.Join(encoded, "\n")
+}
@@ -0,0 +1,49 @@
+// Package mongoparser normalizes MongoDB commands recorded in db.statement.
+package mongoparser
+
+import (
+	"context"
+	"errors"
+
+	"github.com/coralogix/coralogix-opentelemetry-go/processor/internal/jsonredact"
+)
+
+// MongoParse replaces the values of a MongoDB command document, such as the
+// ones recorded by otelmongo, with '?'. The command name and its collection, the
+// first member of the document, and the $db member are kept, as are the field
+// names and operators of filters, so that
+//
+//	{"find":"users","filter":{"age":{"$gt":30}},"$db":"app"}
+//
+// is normalized to
+//
+//	{"find":"users","filter":{"age":{"$gt":"?"}},"$db":"app"}
+func MongoParse(dbStatementStr *string) (string, error) {
+	nodes, err := jsonredact.Parse(*dbStatementStr)
+	if err != nil {
+		return *dbStatementStr, err
+	}
+	if len(nodes) != 1 {
+		return *dbStatementStr, errors.New("mongoparser: expected a single command document")
+	}
+	command := nodes[0]
+	name := command.FirstKey()
+	if name == "" {
+		return *dbStatementStr, errors.New("mongoparser: expected a command document")
+	}
+	command.Redact(func(path []string) bool {
+		return len(path) == 1 && (path[0] == name || path[0] == "$db")
+	})
+	return command.String(), nil
+}
+
+func MongoSpanFormatter(ctx context.Context, method string, query string) string {
+	if query != "" {
+		parsed, err := MongoParse(&query)
+		if err != nil {
+			return method
+		}
+		return parsed
+	}
+	return method
+}
@@ -0,0 +1,82 @@
+package mongoparser
+
+import (
+	"context"
+	"testing"
+)
+
+func TestMongoParse(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+		wantErr  bool
+	}{
+		{
+			name:     "find with operators",
+			input:    `{"find":"users","filter":{"name":"Alice","age":{"$gt":30}},"$db":"app"}`,
+			expected: `{"find":"users","filter":{"name":"?","age":{"$gt":"?"}},"$db":"app"}`,
+		},
+		{
+			name:     "in list",
+			input:    `{"find":"users","filter":{"_id":{"$in":[1,2,3,4]}}}`,
+			expected: `{"find":"users","filter":{"_id":{"$in":["?"]}}}`,
+		},
+		{
+			name:     "insert documents",
+			input:    `{"insert":"users","documents":[{"name":"Alice","age":30},{"name":"Bob","age":41}],"ordered":true}`,
+			expected: `{"insert":"users","documents":[{"name":"?","age":"?"}],"ordered":"?"}`,
+		},
+		{
+			name:     "extended json",
+			input:    `{"delete":"sessions","deletes":[{"q":{"_id":{"$oid":"5f1d7a3b9c1e4a2b3c4d5e6f"}},"limit":1}]}`,
+			expected: `{"delete":"sessions","deletes":[{"q":{"_id":{"$oid":"?"}},"limit":"?"}]}`,
+		},
+		{
+			name:     "aggregate pipeline",
+			input:    `{"aggregate":"orders","pipeline":[{"$match":{"status":"paid"}},{"$group":{"_id":"$customer","total":{"$sum":"$amount"}}}]}`,
+			expected: `{"aggregate":"orders","pipeline":[{"$match":{"status":"?"}},{"$group":{"_id":"?","total":{"$sum":"?"}}}]}`,
+		},
+		{
+			name:     "null kept",
+			input:    `{"find":"users","filter":{"deleted_at":null}}`,
+			expected: `{"find":"users","filter":{"deleted_at":null}}`,
+		},
+		{
+			name:    "not a document",
+			input:   `db.users.find({"name":"Alice"})`,
+			wantErr: true,
+		},
+		{
+			name:    "truncated document",
+			input:   `{"find":"users","filter":{"name":`,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := MongoParse(&tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("MongoParse() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !tt.wantErr && got != tt.expected {
+				t.Errorf("MongoParse() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestMongoSpanFormatter(t *testing.T) {
+	if got := MongoSpanFormatter(context.Background(), "find", ""); got != "find" {
+		t.Errorf("MongoSpanFormatter() = %v, want find", got)
+	}
+	if got := MongoSpanFormatter(context.Background(), "find", "not json"); got != "find" {
+		t.Errorf("MongoSpanFormatter() = %v, want find", got)
+	}
+	got := MongoSpanFormatter(context.Background(), "find", `{"find":"users","filter":{"id":7}}`)
+	if expected := `{"find":"users","filter":{"id":"?"}}`; got != expected {
+		t.Errorf("MongoSpanFormatter() = %v, want %v", got, expected)
+	}
+}
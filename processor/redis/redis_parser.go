@@ -0,0 +1,220 @@
+// Package redisparser normalizes Redis commands recorded in db.statement.
+package redisparser
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+const placeholder = "?"
+
+type keySpec int
+
+const (
+	// firstKey commands take a single key as their first argument.
+	firstKey keySpec = iota
+	// noKeys commands take no key at all.
+	noKeys
+	// allKeys commands take only keys.
+	allKeys
+	// allButLastKeys commands take keys followed by a timeout.
+	allButLastKeys
+	// firstTwoKeys commands take a source and a destination key.
+	firstTwoKeys
+	// alternatingKeys commands take key and value pairs.
+	alternatingKeys
+	// scriptKeys commands take a script, the number of keys and the keys.
+	scriptKeys
+)
+
+var commandKeys = map[string]keySpec{
+	"AUTH": noKeys, "BGSAVE": noKeys, "DBSIZE": noKeys, "DISCARD": noKeys, "ECHO": noKeys,
+	"EXEC": noKeys, "FLUSHALL": noKeys, "FLUSHDB": noKeys, "HELLO": noKeys, "INFO": noKeys,
+	"LASTSAVE": noKeys, "MULTI": noKeys, "PING": noKeys, "QUIT": noKeys, "RANDOMKEY": noKeys,
+	"SAVE": noKeys, "SCAN": noKeys, "SELECT": noKeys, "TIME": noKeys, "UNWATCH": noKeys,
+	"DEL": allKeys, "EXISTS": allKeys, "MGET": allKeys, "PFCOUNT": allKeys, "PFMERGE": allKeys,
+	"PSUBSCRIBE": allKeys, "SDIFF": allKeys, "SDIFFSTORE": allKeys, "SINTER": allKeys,
+	"SINTERSTORE": allKeys, "SUBSCRIBE": allKeys, "SUNION": allKeys, "SUNIONSTORE": allKeys,
+	"TOUCH": allKeys, "UNLINK": allKeys, "WATCH": allKeys,
+	"BLPOP": allButLastKeys, "BRPOP": allButLastKeys, "BZPOPMAX": allButLastKeys, "BZPOPMIN": allButLastKeys,
+	"BLMOVE": firstTwoKeys, "BRPOPLPUSH": firstTwoKeys, "COPY": firstTwoKeys, "LMOVE": firstTwoKeys,
+	"RENAME": firstTwoKeys, "RENAMENX": firstTwoKeys, "RPOPLPUSH": firstTwoKeys, "SMOVE": firstTwoKeys,
+	"MSET": alternatingKeys, "MSETNX": alternatingKeys,
+	"EVAL": scriptKeys, "EVALSHA": scriptKeys, "EVAL_RO": scriptKeys, "EVALSHA_RO": scriptKeys,
+	"FCALL": scriptKeys, "FCALL_RO": scriptKeys,
+}
+
+// subcommandKeys lists the commands named together with their subcommand, and
+// whether the argument following the subcommand is a key.
+var subcommandKeys = map[string]bool{
+	"ACL": false, "CLIENT": false, "CLUSTER": false, "COMMAND": false, "CONFIG": false,
+	"FUNCTION": false, "LATENCY": false, "MODULE": false, "PUBSUB": false, "SCRIPT": false,
+	"SLOWLOG": false, "MEMORY": true, "OBJECT": true, "XGROUP": true, "XINFO": true,
+}
+
+// RedisParse keeps the command and the pattern of the keys of a Redis command
+// and drops its values. Key segments holding identifiers, the ones containing
+// a digit or an '@', are replaced with '?', and the remaining arguments are
+// collapsed into a single '?', so that
+//
+//	SET user:1234:session 7f2c EX 60
+//
+// is normalized to
+//
+//	SET user:?:session ?
+func RedisParse(dbStatementStr *string) (string, error) {
+	args, err := splitArgs(*dbStatementStr)
+	if err != nil {
+		return *dbStatementStr, err
+	}
+	if len(args) == 0 {
+		return *dbStatementStr, errors.New("redisparser: empty command")
+	}
+	command, args := args[0], args[1:]
+	parts := []string{command}
+	name := strings.ToUpper(command)
+	spec := commandKeys[name]
+	if subcommandKey, ok := subcommandKeys[name]; ok {
+		if len(args) == 0 {
+			return command, nil
+		}
+		parts = append(parts, args[0])
+		args = args[1:]
+		spec = firstKey
+		if !subcommandKey {
+			spec = noKeys
+		}
+	}
+	keys, values := splitKeys(spec, args)
+	seen := map[string]bool{}
+	for _, key := range keys {
+		pattern := KeyPattern(key)
+		if seen[pattern] {
+			continue
+		}
+		seen[pattern] = true
+		parts = append(parts, pattern)
+	}
+	if values {
+		parts = append(parts, placeholder)
+	}
+	return strings.Join(parts, " "), nil
+}
+
+// splitKeys returns the keys among args, and whether any other argument is left.
+func splitKeys(spec keySpec, args []string) ([]string, bool) {
+	switch spec {
+	case noKeys:
+		return nil, len(args) > 0
+	case allKeys:
+		return args, false
+	case allButLastKeys:
+		if len(args) < 2 {
+			return args, false
+		}
+		return args[:len(args)-1], true
+	case firstTwoKeys:
+		if len(args) <= 2 {
+			return args, false
+		}
+		return args[:2], true
+	case alternatingKeys:
+		var keys []string
+		for i := 0; i < len(args); i += 2 {
+			keys = append(keys, args[i])
+		}
+		return keys, len(args) > 1
+	case scriptKeys:
+		if len(args) < 2 {
+			return nil, len(args) > 0
+		}
+		count, err := strconv.Atoi(args[1])
+		if err != nil || count < 0 || count > len(args)-2 {
+			return nil, true
+		}
+		return args[2 : 2+count], true
+	}
+	if len(args) == 0 {
+		return nil, false
+	}
+	return args[:1], len(args) > 1
+}
+
+// KeyPattern replaces the segments of a key holding identifiers with '?'.
+// Segments are delimited by ':', '.', '/', '-', '_', '|', '#', '{' and '}'.
+func KeyPattern(key string) string {
+	var b strings.Builder
+	start := 0
+	flush := func(end int) {
+		segment := key[start:end]
+		if strings.ContainsAny(segment, "0123456789@") {
+			segment = placeholder
+		}
+		b.WriteString(segment)
+	}
+	for i, r := range key {
+		if strings.ContainsRune(":./-_|#{}", r) {
+			flush(i)
+			b.WriteRune(r)
+			start = i + 1
+		}
+	}
+	flush(len(key))
+	return b.String()
+}
+
+// splitArgs splits a command line the way redis-cli does, honoring single and
+// double quoted arguments.
+func splitArgs(command string) ([]string, error) {
+	var args []string
+	var current strings.Builder
+	inArg := false
+	var quote rune
+	escaped := false
+	for _, r := range command {
+		switch {
+		case escaped:
+			current.WriteRune(r)
+			escaped = false
+		case quote != 0 && r == '\\' && quote == '"':
+			escaped = true
+		case quote != 0 && r == quote:
+			quote = 0
+		case quote != 0:
+			current.WriteRune(r)
+		case r == '"' || r == '\'':
+			quote = r
+			inArg = true
+		case unicode.IsSpace(r):
+			if inArg {
+				args = append(args, current.String())
+				current.Reset()
+				inArg = false
+			}
+		default:
+			current.WriteRune(r)
+			inArg = true
+		}
+	}
+	if quote != 0 {
+		return nil, errors.New("redisparser: unterminated quoted argument")
+	}
+	if inArg {
+		args = append(args, current.String())
+	}
+	return args, nil
+}
+
+func RedisSpanFormatter(ctx context.Context, method string, query string) string {
+	if query != "" {
+		parsed, err := RedisParse(&query)
+		if err != nil {
+			return method
+		}
+		return parsed
+	}
+	return method
+}
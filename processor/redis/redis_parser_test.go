@@ -0,0 +1,55 @@
+package redisparser
+
+import (
+	"context"
+	"testing"
+)
+
+func TestRedisParse(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+		wantErr  bool
+	}{
+		{name: "get", input: "GET user:1234", expected: "GET user:?"},
+		{name: "set with options", input: "SET user:1234:session 7f2c EX 60", expected: "SET user:?:session ?"},
+		{name: "lowercase command", input: "hset cart:42 item:9 3", expected: "hset cart:? ?"},
+		{name: "quoted value", input: `SET greeting "hello world"`, expected: "SET greeting ?"},
+		{name: "email in key", input: "GET user:alice@example.com", expected: "GET user:?.com"},
+		{name: "multiple keys", input: "MGET user:1 user:2 user:3 order:7", expected: "MGET user:? order:?"},
+		{name: "key value pairs", input: "MSET a:1 x b:2 y", expected: "MSET a:? b:? ?"},
+		{name: "blocking pop", input: "BLPOP queue:1 queue:2 5", expected: "BLPOP queue:? ?"},
+		{name: "rename", input: "RENAME tmp:1 cache:1", expected: "RENAME tmp:? cache:?"},
+		{name: "eval", input: "EVAL \"return redis.call('GET', KEYS[1])\" 1 user:8 arg", expected: "EVAL user:? ?"},
+		{name: "no keys", input: "AUTH secret", expected: "AUTH ?"},
+		{name: "ping", input: "PING", expected: "PING"},
+		{name: "subcommand", input: "CONFIG SET maxmemory 100mb", expected: "CONFIG SET ?"},
+		{name: "subcommand with key", input: "OBJECT ENCODING user:5", expected: "OBJECT ENCODING user:?"},
+		{name: "hash tag", input: "GET {tenant42}.profile", expected: "GET {?}.profile"},
+		{name: "empty", input: "  ", wantErr: true},
+		{name: "unterminated quote", input: `SET key "value`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := RedisParse(&tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("RedisParse() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !tt.wantErr && got != tt.expected {
+				t.Errorf("RedisParse() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestRedisSpanFormatter(t *testing.T) {
+	if got := RedisSpanFormatter(context.Background(), "get", ""); got != "get" {
+		t.Errorf("RedisSpanFormatter() = %v, want get", got)
+	}
+	if got := RedisSpanFormatter(context.Background(), "get", "get user:9"); got != "get user:?" {
+		t.Errorf("RedisSpanFormatter() = %v, want get user:?", got)
+	}
+}
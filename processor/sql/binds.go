@@ -0,0 +1,50 @@
+package sqlparser
+
+import (
+	"fmt"
+	"strings"
+)
+
+// bindPrefix names the binds handed to the MySQL parser, which only supports ?
+// and :name binds, and renames ? ones to :v1, :v2... as it reads them.
+const bindPrefix = ":cx_bind_"
+
+// rewriteStatement prepares statement for a parser: comments are dropped, so
+// that sqlcommenter tags and hints neither break the parser nor reach span
+// names, money literals, which the parsers reject, are replaced with a number,
+// and bind placeholders are renamed with rename, unless it is nil. Literals and
+// identifiers are left untouched, so that '$100' or $$...$$ strings are not
+// mistaken for binds.
+func (d lexicalDialect) rewriteStatement(statement string, rename func(placeholder string) string) (string, error) {
+	tokens, err := d.tokenize(statement)
+	if err != nil {
+		return "", err
+	}
+	var b strings.Builder
+	for i, t := range tokens {
+		if t.spaced && i > 0 {
+			b.WriteByte(' ')
+		}
+		switch {
+		case t.kind == tokenPlaceholder && rename != nil:
+			b.WriteString(rename(t.text))
+		case t.kind == tokenLiteral && strings.HasPrefix(t.text, "$") && !isDollarQuote([]rune(t.text), 0):
+			b.WriteString("0")
+		default:
+			b.WriteString(t.text)
+		}
+	}
+	return b.String(), nil
+}
+
+// mysqlBinds renames the binds of a statement for the MySQL parser, returning
+// the original placeholders by name.
+func mysqlBinds(statement string) (string, map[string]string, error) {
+	binds := map[string]string{}
+	rewritten, err := mysqlLexicalDialect.rewriteStatement(statement, func(placeholder string) string {
+		name := fmt.Sprintf("%s%d", bindPrefix, len(binds)+1)
+		binds[name] = placeholder
+		return name
+	})
+	return rewritten, binds, err
+}
@@ -0,0 +1,51 @@
+package sqlparser
+
+import "context"
+
+type contextKey int
+
+const (
+	queryNameKey contextKey = iota
+	normalizationDisabledKey
+)
+
+// WithQueryName returns a copy of ctx in which the span formatters name the
+// spans of its queries name, e.g. GetUserByID, without parsing them.
+func WithQueryName(ctx context.Context, name string) context.Context {
+	return context.WithValue(ctx, queryNameKey, name)
+}
+
+// QueryName returns the query name set on ctx by WithQueryName.
+func QueryName(ctx context.Context) (string, bool) {
+	name, ok := ctx.Value(queryNameKey).(string)
+	return name, ok && name != ""
+}
+
+// WithoutNormalization returns a copy of ctx in which the span formatters skip
+// parsing and name the spans of its queries after their method, e.g. for
+// statements too large or too dynamic to yield a useful name.
+func WithoutNormalization(ctx context.Context) context.Context {
+	return context.WithValue(ctx, normalizationDisabledKey, true)
+}
+
+// NormalizationDisabled reports whether WithoutNormalization was applied to ctx.
+func NormalizationDisabled(ctx context.Context) bool {
+	disabled, _ := ctx.Value(normalizationDisabledKey).(bool)
+	return disabled
+}
+
+// ContextSpanName returns the span name the overrides of ctx impose on a query
+// of method, if any: the name set by WithQueryName, or method when
+// WithoutNormalization was applied. The query name wins.
+func ContextSpanName(ctx context.Context, method string) (string, bool) {
+	if ctx == nil {
+		return "", false
+	}
+	if name, ok := QueryName(ctx); ok {
+		return name, true
+	}
+	if NormalizationDisabled(ctx) {
+		return method, true
+	}
+	return "", false
+}
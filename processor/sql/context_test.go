@@ -0,0 +1,59 @@
+package sqlparser
+
+import (
+	"context"
+	"testing"
+)
+
+func TestSpanFormatterContextOverrides(t *testing.T) {
+	query := "SELECT * FROM users WHERE id = 1"
+	tests := []struct {
+		name     string
+		ctx      context.Context
+		expected string
+	}{
+		{
+			name:     "no override",
+			ctx:      context.Background(),
+			expected: "SELECT * FROM users WHERE id = '?'",
+		},
+		{
+			name:     "query name",
+			ctx:      WithQueryName(context.Background(), "GetUserByID"),
+			expected: "GetUserByID",
+		},
+		{
+			name:     "empty query name is ignored",
+			ctx:      WithQueryName(context.Background(), ""),
+			expected: "SELECT * FROM users WHERE id = '?'",
+		},
+		{
+			name:     "normalization disabled",
+			ctx:      WithoutNormalization(context.Background()),
+			expected: "query",
+		},
+		{
+			name:     "query name wins over disabled normalization",
+			ctx:      WithQueryName(WithoutNormalization(context.Background()), "GetUserByID"),
+			expected: "GetUserByID",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := PostgresqlSpanFormatter(tt.ctx, "query", query); got != tt.expected {
+				t.Errorf("PostgresqlSpanFormatter() = %v, want %v", got, tt.expected)
+			}
+			if got := (RedactionPolicy{}).PostgresqlSpanFormatter(tt.ctx, "query", query); got != tt.expected {
+				t.Errorf("RedactionPolicy.PostgresqlSpanFormatter() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestSpanFormatterQueryNameSkipsParsing(t *testing.T) {
+	ctx := WithQueryName(context.Background(), "ListOrders")
+	if got := MysqlSpanFormatter(ctx, "query", "SELECT * FROM"); got != "ListOrders" {
+		t.Errorf("MysqlSpanFormatter() = %v, want ListOrders", got)
+	}
+}
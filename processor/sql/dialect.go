@@ -0,0 +1,66 @@
+package sqlparser
+
+import (
+	"errors"
+	"strings"
+)
+
+var errUnknownDialect = errors.New("sqlparser: unknown db.system")
+
+type dialect struct {
+	parse         func(*string) (string, error)
+	statementInfo func(string) (StatementInfo, error)
+	// describe normalizes statements according to a RedactionPolicy.
+	describe func(string, RedactionPolicy) (StatementInfo, error)
+	// lexical redacts the statements the dialect fails to normalize.
+	lexical lexicalDialect
+}
+
+// dialects maps db.system and db.system.name values to the functions
+// normalizing their statements.
+var dialects = map[string]dialect{
+	"mysql":                {parse: MysqlParse, statementInfo: MysqlStatementInfo, describe: mysqlScriptInfo, lexical: mysqlLexicalDialect},
+	"mariadb":              {parse: MysqlParse, statementInfo: MysqlStatementInfo, describe: mysqlScriptInfo, lexical: mysqlLexicalDialect},
+	"postgresql":           {parse: PostgresqlParse, statementInfo: PostgresqlStatementInfo, describe: postgresqlPolicyInfo, lexical: postgresqlLexicalDialect},
+	"sqlite":               {parse: SqliteParse, statementInfo: SqliteStatementInfo, describe: sqliteDialect.statementInfo, lexical: sqliteDialect},
+	"mssql":                {parse: MssqlParse, statementInfo: MssqlStatementInfo, describe: mssqlDialect.statementInfo, lexical: mssqlDialect},
+	"microsoft.sql_server": {parse: MssqlParse, statementInfo: MssqlStatementInfo, describe: mssqlDialect.statementInfo, lexical: mssqlDialect},
+	"oracle":               {parse: OracleParse, statementInfo: OracleStatementInfo, describe: oracleDialect.statementInfo, lexical: oracleDialect},
+	"oracle.db":            {parse: OracleParse, statementInfo: OracleStatementInfo, describe: oracleDialect.statementInfo, lexical: oracleDialect},
+}
+
+func postgresqlPolicyInfo(query string, policy RedactionPolicy) (StatementInfo, error) {
+	return postgresqlScriptInfo(query, RedactAll, policy)
+}
+
+// DialectParser returns the statement parser registered for a db.system value.
+func DialectParser(dbSystem string) (func(*string) (string, error), bool) {
+	d, ok := dialects[strings.ToLower(dbSystem)]
+	return d.parse, ok
+}
+
+// DialectStatementInfo returns the StatementInfo function registered for a db.system value.
+func DialectStatementInfo(dbSystem string) (func(string) (StatementInfo, error), bool) {
+	d, ok := dialects[strings.ToLower(dbSystem)]
+	return d.statementInfo, ok
+}
+
+func dialectDescriber(dbSystem string) (func(string, RedactionPolicy) (StatementInfo, error), bool) {
+	d, ok := dialects[strings.ToLower(dbSystem)]
+	return d.describe, ok
+}
+
+// redactLexically replaces every literal of query with a placeholder, relying
+// only on the lexical rules of the dialect, for the statements it fails to
+// normalize: unparsable, too large or too slow to parse.
+func redactLexically(dbSystem string, query string) (string, error) {
+	d, ok := dialects[strings.ToLower(dbSystem)]
+	if !ok {
+		return "", errUnknownDialect
+	}
+	tokens, err := d.lexical.normalize(query, RedactionPolicy{})
+	if err != nil {
+		return "", err
+	}
+	return renderTokens(tokens), nil
+}
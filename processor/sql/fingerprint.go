@@ -0,0 +1,119 @@
+package sqlparser
+
+import (
+	"fmt"
+	"hash/fnv"
+	"strings"
+	"unicode"
+)
+
+// QueryFingerprintAttribute is the span attribute holding the statement fingerprint.
+const QueryFingerprintAttribute = "db.query.fingerprint"
+
+// Fingerprint returns a stable hash of a normalized statement. Case, whitespace,
+// identifier quoting, grouping parentheses and literal/placeholder styles are
+// canonicalized first, so the same statement normalized by different dialects
+// or written with different literals maps to the same fingerprint.
+func Fingerprint(normalized string) string {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(canonicalStatement(normalized)))
+	return fmt.Sprintf("%016x", h.Sum64())
+}
+
+// MysqlFingerprint normalizes a MySQL statement and returns its fingerprint.
+// Statements the parser rejects are fingerprinted from their raw text.
+func MysqlFingerprint(query string) string {
+	return fingerprint(MysqlParse, query)
+}
+
+// PostgresqlFingerprint normalizes a PostgreSQL statement and returns its fingerprint.
+// Statements the parser rejects are fingerprinted from their raw text.
+func PostgresqlFingerprint(query string) string {
+	return fingerprint(PostgresqlParse, query)
+}
+
+func fingerprint(parse func(*string) (string, error), query string) string {
+	raw := query
+	parsed, err := parse(&query)
+	if err != nil {
+		return Fingerprint(raw)
+	}
+	return Fingerprint(parsed)
+}
+
+// canonicalStatement rewrites a statement as space separated lowercase tokens,
+// with every literal and bind placeholder replaced by "?", parentheses, brackets
+// and the implicit ASC ordering dropped.
+func canonicalStatement(statement string) string {
+	var tokens []string
+	runes := []rune(statement)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r), r == '(', r == ')', r == '[', r == ']':
+			i++
+		case r == ':' && i+1 < len(runes) && runes[i+1] == ':':
+			tokens = append(tokens, "::")
+			i += 2
+		case (r == ':' || r == '@') && i+1 < len(runes) && (unicode.IsLetter(runes[i+1]) || unicode.IsDigit(runes[i+1])):
+			i = skipWord(runes, i+1)
+			tokens = append(tokens, "?")
+		case r == '?':
+			i++
+			for i < len(runes) && unicode.IsDigit(runes[i]) {
+				i++
+			}
+			tokens = append(tokens, "?")
+		case (r == 'n' || r == 'N') && i+1 < len(runes) && runes[i+1] == '\'':
+			i = skipQuoted(runes, i+1, '\'')
+			tokens = append(tokens, "?")
+		case r == '\'':
+			i = skipQuoted(runes, i, '\'')
+			tokens = append(tokens, "?")
+		case r == '"' || r == '`':
+			end := skipQuoted(runes, i, r)
+			tokens = append(tokens, strings.ToLower(string(runes[i+1:max(i+1, end-1)])))
+			i = end
+		case unicode.IsDigit(r), r == '$' && i+1 < len(runes) && unicode.IsDigit(runes[i+1]):
+			i++
+			for i < len(runes) && (unicode.IsDigit(runes[i]) || runes[i] == '.') {
+				i++
+			}
+			tokens = append(tokens, "?")
+		case unicode.IsLetter(r) || r == '_':
+			start := i
+			for i < len(runes) && (unicode.IsLetter(runes[i]) || unicode.IsDigit(runes[i]) || runes[i] == '_' || runes[i] == '$') {
+				i++
+			}
+			// ascending is the default order; MySQL prints it, PostgreSQL omits it
+			if word := strings.ToLower(string(runes[start:i])); word != "asc" {
+				tokens = append(tokens, word)
+			}
+		default:
+			tokens = append(tokens, string(r))
+			i++
+		}
+	}
+	return strings.Join(tokens, " ")
+}
+
+// skipQuoted returns the index just past the quoted section starting at start,
+// treating a doubled quote or a backslash escape as part of the section.
+func skipQuoted(runes []rune, start int, quote rune) int {
+	i := start + 1
+	for i < len(runes) {
+		if runes[i] == '\\' && quote == '\'' {
+			i += 2
+			continue
+		}
+		if runes[i] == quote {
+			if i+1 < len(runes) && runes[i+1] == quote {
+				i += 2
+				continue
+			}
+			return i + 1
+		}
+		i++
+	}
+	return len(runes)
+}
@@ -0,0 +1,141 @@
+package sqlparser
+
+import (
+	"context"
+	"testing"
+
+	"go.opentelemetry.io/otel/attribute"
+)
+
+func TestFingerprint(t *testing.T) {
+	tests := []struct {
+		name        string
+		fingerprint func(string) string
+		queries     []string
+	}{
+		{
+			name:        "mysql different literals",
+			fingerprint: MysqlFingerprint,
+			queries: []string{
+				"SELECT * FROM users WHERE id = 123",
+				"SELECT * FROM users WHERE id = 456",
+				"select *   from users\n\twhere id = 'abc'",
+			},
+		},
+		{
+			name:        "postgresql different literals",
+			fingerprint: PostgresqlFingerprint,
+			queries: []string{
+				"SELECT * FROM users WHERE age > 18 AND name = 'Alice'",
+				"SELECT * FROM users WHERE age > 65 AND name = 'Bob'",
+				"select * from USERS where AGE > $1 and NAME = $2",
+			},
+		},
+		{
+			name:        "postgresql batch sizes",
+			fingerprint: PostgresqlFingerprint,
+			queries: []string{
+				"INSERT INTO users (id, name) VALUES (1, 'a')",
+				"INSERT INTO users (id, name) VALUES (1, 'a'), (2, 'b'), (3, 'c')",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expected := tt.fingerprint(tt.queries[0])
+			for _, query := range tt.queries[1:] {
+				if got := tt.fingerprint(query); got != expected {
+					t.Errorf("fingerprint(%q) = %v, want %v", query, got, expected)
+				}
+			}
+		})
+	}
+}
+
+func TestFingerprintAcrossDialects(t *testing.T) {
+	queries := []string{
+		"SELECT u.name FROM users AS u JOIN orders AS o ON u.id = o.user_id WHERE o.total > 10 AND u.status = 'active'",
+		"SELECT * FROM users WHERE id IN (1, 2, 3) ORDER BY name",
+		"INSERT INTO users (id, name) VALUES (1, 'a'), (2, 'b')",
+		"UPDATE users SET name = 'a' WHERE id = 1",
+		"SELECT * FROM users WHERE active = true AND id = 5",
+		"UPDATE accounts SET balance = -1, flagged = FALSE WHERE id IN (-1, 2) AND total = -2.5",
+		"SELECT * FROM stock WHERE qty - 1 > 0 AND -qty < +5",
+	}
+	for _, query := range queries {
+		expected := PostgresqlFingerprint(query)
+		for _, dbSystem := range []string{"mysql", "sqlite", "mssql", "oracle"} {
+			parse, _ := DialectParser(dbSystem)
+			q := query
+			normalized, err := parse(&q)
+			if err != nil {
+				t.Fatalf("%s: parse(%q) error = %v", dbSystem, query, err)
+			}
+			if got := Fingerprint(normalized); got != expected {
+				t.Errorf("fingerprint(%q) differs across dialects: %s %v (%s), postgresql %v", query, dbSystem, got, normalized, expected)
+			}
+		}
+	}
+}
+
+func TestFingerprintDistinguishesStatements(t *testing.T) {
+	queries := []string{
+		"SELECT * FROM users WHERE id = 1",
+		"SELECT * FROM orders WHERE id = 1",
+		"SELECT name FROM users WHERE id = 1",
+		"DELETE FROM users WHERE id = 1",
+		"SELECT * FROM users WHERE id > 1",
+	}
+	seen := map[string]string{}
+	for _, query := range queries {
+		fingerprint := PostgresqlFingerprint(query)
+		if other, ok := seen[fingerprint]; ok {
+			t.Errorf("fingerprint(%q) collides with %q", query, other)
+		}
+		seen[fingerprint] = query
+	}
+}
+
+func TestFingerprintUnparsableQuery(t *testing.T) {
+	a := MysqlFingerprint("SELEC * FROM users WHERE name = 'Alice' AND id = 1")
+	b := MysqlFingerprint("SELEC * FROM users WHERE name = 'Bob' AND id = 2")
+	if a != b {
+		t.Errorf("fingerprints of unparsable queries differ: %v, %v", a, b)
+	}
+}
+
+func TestFingerprintAttributes(t *testing.T) {
+	attributes := PostgresqlFingerprintAttributes(context.Background(), "sql.conn.query", "SELECT * FROM users WHERE id = 1")
+	if len(attributes) != 1 || string(attributes[0].Key) != QueryFingerprintAttribute {
+		t.Fatalf("postgresqlFingerprintAttributes() = %v", attributes)
+	}
+	if attributes[0].Value.AsString() != PostgresqlFingerprint("SELECT * FROM users WHERE id = 2") {
+		t.Errorf("postgresqlFingerprintAttributes() = %v", attributes[0].Value.AsString())
+	}
+	if attributes := MysqlFingerprintAttributes(context.Background(), "sql.conn.query", ""); attributes != nil {
+		t.Errorf("mysqlFingerprintAttributes() with empty query = %v", attributes)
+	}
+	for _, ctx := range []context.Context{
+		WithQueryName(context.Background(), "GetUser"),
+		WithoutNormalization(context.Background()),
+	} {
+		if attributes := PostgresqlFingerprintAttributes(ctx, "sql.conn.query", "SELECT * FROM users WHERE id = 1"); attributes != nil {
+			t.Errorf("postgresqlFingerprintAttributes() with context overrides = %v", attributes)
+		}
+	}
+}
+
+func TestFingerprintLexicalDialects(t *testing.T) {
+	expected := PostgresqlFingerprint("SELECT name FROM users WHERE id = 1 AND status = 'active'")
+	queries := map[string]func(context.Context, string, string) []attribute.KeyValue{
+		"SELECT [name] FROM [users] WHERE [id] = @p1 AND [status] = N'closed'": MssqlFingerprintAttributes,
+		"SELECT name FROM users WHERE id = :1 AND status = q'[active]'":        OracleFingerprintAttributes,
+		"SELECT \"name\" FROM users WHERE id = ?1 AND status = 'x'":            SqliteFingerprintAttributes,
+	}
+	for query, attributes := range queries {
+		if got := attributes(context.Background(), "query", query)[0].Value.AsString(); got != expected {
+			t.Errorf("fingerprint(%q) = %v, want %v", query, got, expected)
+		}
+	}
+}
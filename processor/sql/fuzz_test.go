@@ -0,0 +1,101 @@
+package sqlparser
+
+import (
+	"context"
+	"testing"
+)
+
+var fuzzSeeds = []string{
+	"SELECT * FROM users WHERE id = 1",
+	"INSERT INTO t (a, b) VALUES (1, 'x'), (2, 'y')",
+	"UPDATE t SET a = $1 WHERE b IN (1, 2, 3)",
+	"SELECT $$a;b$$, $tag$x$tag$ FROM t",
+	"CALL p(1, 'a'); SELECT 1",
+	"SELECT 1 /*controller='users'*/",
+	"SELECT '",
+	"SELECT (((((((((1)))))))))",
+	"WITH a AS (SELECT 1) SELECT * FROM a WHERE x = ANY(ARRAY[1, 2])",
+	"EXEC dbo.p @a = N'x'",
+	"SELECT q'[it's]' FROM dual",
+}
+
+// fuzzFormatter checks that no parser panics behind a span formatter and that
+// spans are always named.
+func fuzzFormatter(f *testing.F, formatter func(context.Context, string, string) string) {
+	for _, seed := range fuzzSeeds {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, query string) {
+		before := ParseFailureCounts().Panics
+		name := formatter(context.Background(), "query", query)
+		if name == "" && query != "" {
+			t.Errorf("empty span name for %q", query)
+		}
+		if ParseFailureCounts().Panics != before {
+			t.Errorf("parser panicked on %q", query)
+		}
+	})
+}
+
+func FuzzMysqlSpanFormatter(f *testing.F) {
+	fuzzFormatter(f, MysqlSpanFormatter)
+}
+
+func FuzzPostgresqlSpanFormatter(f *testing.F) {
+	fuzzFormatter(f, PostgresqlSpanFormatter)
+}
+
+func FuzzSqliteSpanFormatter(f *testing.F) {
+	fuzzFormatter(f, SqliteSpanFormatter)
+}
+
+func FuzzMssqlSpanFormatter(f *testing.F) {
+	fuzzFormatter(f, MssqlSpanFormatter)
+}
+
+func FuzzOracleSpanFormatter(f *testing.F) {
+	fuzzFormatter(f, OracleSpanFormatter)
+}
+
+// fuzzParse checks the invariants of a statement parser: it never panics, its
+// output is normalized again to itself and no literal survives in it.
+func fuzzParse(f *testing.F, parse func(*string) (string, error), d lexicalDialect) {
+	for _, seed := range fuzzSeeds {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, query string) {
+		before := ParseFailureCounts().Panics
+		normalized, err := parse(&query)
+		if ParseFailureCounts().Panics != before {
+			t.Fatalf("parser panicked on %q", query)
+		}
+		if err != nil {
+			return
+		}
+		again := normalized
+		renormalized, err := parse(&again)
+		if err != nil {
+			t.Fatalf("normalized %q of %q does not parse: %v", normalized, query, err)
+		}
+		if renormalized != normalized {
+			t.Fatalf("normalizing %q twice gives %q then %q", query, normalized, renormalized)
+		}
+		tokens, err := d.tokenize(normalized)
+		if err != nil {
+			t.Fatalf("normalized %q of %q does not tokenize: %v", normalized, query, err)
+		}
+		for _, tok := range tokens {
+			if tok.kind == tokenLiteral && tok.text != "?" && tok.text != "'?'" {
+				t.Fatalf("literal %s of %q survives in %q", tok.text, query, normalized)
+			}
+		}
+	})
+}
+
+func FuzzMysqlParse(f *testing.F) {
+	fuzzParse(f, MysqlParse, mysqlLexicalDialect)
+}
+
+func FuzzPostgresqlParse(f *testing.F) {
+	fuzzParse(f, PostgresqlParse, postgresqlLexicalDialect)
+}
@@ -0,0 +1,107 @@
+package sqlparser
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "update the golden files in testdata/golden")
+
+// goldenDialects are the db.system values each golden statement is normalized for.
+var goldenDialects = []string{"mysql", "postgresql", "sqlite", "mssql", "oracle"}
+
+// goldenPolicy normalizes the policy_*.sql golden statements.
+var goldenPolicy = RedactionPolicy{AllowedColumns: []string{"status"}}
+
+// TestGolden normalizes every testdata/golden/*.sql statement for each dialect
+// and compares the results with the .golden file next to it, which holds one
+// "-- <dialect>" section per dialect. The policy_*.sql statements are
+// normalized with goldenPolicy. Run with -update to rewrite them.
+func TestGolden(t *testing.T) {
+	inputs, err := filepath.Glob(filepath.Join("testdata", "golden", "*.sql"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(inputs) == 0 {
+		t.Fatal("no golden statements found")
+	}
+	for _, input := range inputs {
+		name := strings.TrimSuffix(filepath.Base(input), ".sql")
+		t.Run(name, func(t *testing.T) {
+			query, err := os.ReadFile(input)
+			if err != nil {
+				t.Fatal(err)
+			}
+			actual := goldenOutput(strings.TrimSuffix(string(query), "\n"), strings.HasPrefix(name, "policy_"))
+			golden := strings.TrimSuffix(input, ".sql") + ".golden"
+			if *update {
+				if err := os.WriteFile(golden, []byte(actual), 0o644); err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+			expected, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatalf("%v, run the tests with -update to create it", err)
+			}
+			if actual != string(expected) {
+				t.Errorf("%s differs from the golden file\n--- got\n%s--- want\n%s", input, actual, expected)
+			}
+		})
+	}
+}
+
+func goldenOutput(query string, withPolicy bool) string {
+	var b strings.Builder
+	for _, dbSystem := range goldenDialects {
+		var normalized string
+		var err error
+		if withPolicy {
+			var info StatementInfo
+			info, err = goldenPolicy.StatementInfo(dbSystem, query)
+			normalized = info.Normalized
+		} else {
+			parse, _ := DialectParser(dbSystem)
+			q := query
+			normalized, err = parse(&q)
+		}
+		if err != nil {
+			normalized = "error: " + err.Error()
+		}
+		fmt.Fprintf(&b, "-- %s\n%s\n", dbSystem, normalized)
+	}
+	return b.String()
+}
+
+// literalResidue matches what is left of a literal redacted in pieces, or not
+// at all: a number or a boolean, or a sign, a prefix or a suffix next to a
+// placeholder.
+var literalResidue = regexp.MustCompile(`(^|[^$?:@\w.])\d|[=,(] ?(?i:true|false)\b|[-+$]'?\?|\$\d+ '?\?|\b[A-Za-z] '?\?|\?'? [A-Za-z]\b`)
+
+// TestGoldenRedactsLiterals checks that the golden files hold no piece of a
+// literal, so that -update cannot bless one.
+func TestGoldenRedactsLiterals(t *testing.T) {
+	goldens, err := filepath.Glob(filepath.Join("testdata", "golden", "*.golden"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, golden := range goldens {
+		content, err := os.ReadFile(golden)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, line := range strings.Split(string(content), "\n") {
+			if strings.HasPrefix(line, "-- ") || strings.HasPrefix(line, "error: ") {
+				continue
+			}
+			if residue := literalResidue.FindString(line); residue != "" {
+				t.Errorf("%s: %q holds %q", golden, line, residue)
+			}
+		}
+	}
+}
@@ -0,0 +1,203 @@
+package sqlparser
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/metric/noop"
+)
+
+// The parsers run inside span name callbacks, on the request path. A statement
+// that makes a parser panic or go pathological must not crash or stall the
+// request, so every parse is guarded: oversized statements are rejected, panics
+// are recovered and the parses of large statements are abandoned after a time
+// budget. Small statements parse in microseconds and are parsed inline, without
+// the goroutine and timer the budget takes. Callers then fall back as for any
+// unparsable statement, e.g. to the method name. Abandoned parses keep running,
+// so once too many are in flight, statements are redacted by the lexer of the
+// dialect instead, inline.
+
+var (
+	// ErrStatementTooLarge is returned for statements over ParseLimits.MaxInputSize.
+	ErrStatementTooLarge = errors.New("sqlparser: statement too large")
+	// ErrParseTimeout is returned when parsing exceeds ParseLimits.Timeout.
+	ErrParseTimeout = errors.New("sqlparser: parse timed out")
+	// ErrParsePanic is returned when a parser panicked.
+	ErrParsePanic = errors.New("sqlparser: parser panicked")
+	// ErrParseOverloaded is returned when ParseLimits.MaxInFlight parses are
+	// running and the statement cannot be redacted lexically instead.
+	ErrParseOverloaded = errors.New("sqlparser: too many parses in flight")
+)
+
+// ParseLimits bounds the work spent normalizing a statement.
+type ParseLimits struct {
+	// MaxInputSize is the size in bytes above which statements are not parsed.
+	// Zero means no limit.
+	MaxInputSize int
+	// Timeout is the time budget of a parse. The parse keeps running in the
+	// background once abandoned, as Go cannot interrupt it. Zero means no limit.
+	Timeout time.Duration
+	// TimeoutMinSize is the size in bytes from which parses are subject to
+	// Timeout; smaller statements are parsed inline. Zero subjects every parse
+	// to Timeout.
+	TimeoutMinSize int
+	// MaxInFlight is the number of parses subject to Timeout running at once,
+	// abandoned ones included, over which statements are redacted lexically.
+	// Zero means no limit.
+	MaxInFlight int
+}
+
+// DefaultParseLimits are the limits in effect until SetParseLimits is called.
+var DefaultParseLimits = ParseLimits{
+	MaxInputSize:   64 << 10,
+	Timeout:        100 * time.Millisecond,
+	TimeoutMinSize: 4 << 10,
+	MaxInFlight:    64,
+}
+
+var (
+	limitsMu sync.RWMutex
+	limits   = DefaultParseLimits
+)
+
+// SetParseLimits replaces the limits applied to every parse.
+func SetParseLimits(l ParseLimits) {
+	limitsMu.Lock()
+	defer limitsMu.Unlock()
+	limits = l
+}
+
+func currentParseLimits() ParseLimits {
+	limitsMu.RLock()
+	defer limitsMu.RUnlock()
+	return limits
+}
+
+// ParseFailures counts the statements the guards gave up on since the process
+// started. They are also counted by the sql.parse.failures counter.
+type ParseFailures struct {
+	Oversized uint64
+	Timeouts  uint64
+	Panics    uint64
+	// Overloaded counts the statements redacted lexically, or not at all,
+	// because of MaxInFlight.
+	Overloaded uint64
+}
+
+var (
+	oversized, timeouts, panics, overloaded atomic.Uint64
+	inFlight                                atomic.Int64
+)
+
+// ParseFailureCounts returns the current ParseFailures counters.
+func ParseFailureCounts() ParseFailures {
+	return ParseFailures{
+		Oversized:  oversized.Load(),
+		Timeouts:   timeouts.Load(),
+		Panics:     panics.Load(),
+		Overloaded: overloaded.Load(),
+	}
+}
+
+const (
+	instrumentationName = "github.com/coralogix/coralogix-opentelemetry-go/processor/sql"
+
+	// ParseFailuresMetric counts the statements the guards gave up on, by
+	// ParseFailureReasonAttribute.
+	ParseFailuresMetric = "sql.parse.failures"
+	// ParseFailureReasonAttribute is oversized, timeout, panic or overloaded.
+	ParseFailureReasonAttribute = "reason"
+)
+
+var (
+	metricsMu           sync.RWMutex
+	parseFailureCounter = newParseFailureCounter(otel.GetMeterProvider())
+)
+
+// SetMeterProvider sets the provider of the sql.parse.failures counter,
+// otel.GetMeterProvider() by default.
+func SetMeterProvider(provider metric.MeterProvider) {
+	counter := newParseFailureCounter(provider)
+	metricsMu.Lock()
+	defer metricsMu.Unlock()
+	parseFailureCounter = counter
+}
+
+func newParseFailureCounter(provider metric.MeterProvider) metric.Int64Counter {
+	counter, err := provider.Meter(instrumentationName).Int64Counter(ParseFailuresMetric,
+		metric.WithDescription("Statements the SQL parsers gave up on."),
+		metric.WithUnit("{statement}"),
+	)
+	if err != nil {
+		otel.Handle(err)
+		return noop.Int64Counter{}
+	}
+	return counter
+}
+
+func countFailure(count *atomic.Uint64, reason string) {
+	count.Add(1)
+	metricsMu.RLock()
+	counter := parseFailureCounter
+	metricsMu.RUnlock()
+	counter.Add(context.Background(), 1, metric.WithAttributes(attribute.String(ParseFailureReasonAttribute, reason)))
+}
+
+// guard runs describe on query within the current ParseLimits, recovering
+// from panics. fallback, if any, redacts query lexically when MaxInFlight
+// parses are running.
+func guard(query string, describe func() (StatementInfo, error), fallback func() (StatementInfo, error)) (StatementInfo, error) {
+	l := currentParseLimits()
+	if l.MaxInputSize > 0 && len(query) > l.MaxInputSize {
+		countFailure(&oversized, "oversized")
+		return StatementInfo{}, ErrStatementTooLarge
+	}
+	if l.Timeout <= 0 || len(query) < l.TimeoutMinSize {
+		return recovered(describe)
+	}
+	if n := inFlight.Add(1); l.MaxInFlight > 0 && n > int64(l.MaxInFlight) {
+		inFlight.Add(-1)
+		countFailure(&overloaded, "overloaded")
+		if fallback == nil {
+			return StatementInfo{}, ErrParseOverloaded
+		}
+		return recovered(fallback)
+	}
+
+	type result struct {
+		info StatementInfo
+		err  error
+	}
+	done := make(chan result, 1)
+	go func() {
+		defer inFlight.Add(-1)
+		info, err := recovered(describe)
+		done <- result{info: info, err: err}
+	}()
+	timer := time.NewTimer(l.Timeout)
+	defer timer.Stop()
+	select {
+	case r := <-done:
+		return r.info, r.err
+	case <-timer.C:
+		countFailure(&timeouts, "timeout")
+		return StatementInfo{}, ErrParseTimeout
+	}
+}
+
+func recovered(describe func() (StatementInfo, error)) (info StatementInfo, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			countFailure(&panics, "panic")
+			info, err = StatementInfo{}, fmt.Errorf("%w: %v", ErrParsePanic, r)
+		}
+	}()
+	return describe()
+}
@@ -0,0 +1,184 @@
+package sqlparser
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	metricSdk "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+func withParseLimits(t *testing.T, l ParseLimits) {
+	t.Helper()
+	SetParseLimits(l)
+	t.Cleanup(func() { SetParseLimits(DefaultParseLimits) })
+}
+
+func TestGuardRejectsOversizedStatements(t *testing.T) {
+	withParseLimits(t, ParseLimits{MaxInputSize: 64})
+	before := ParseFailureCounts()
+
+	query := "SELECT * FROM users WHERE id IN (" + strings.Repeat("1, ", 40) + "1)"
+	if got := MysqlSpanFormatter(context.Background(), "query", query); got != "query" {
+		t.Errorf("MysqlSpanFormatter() = %v, want query", got)
+	}
+	if _, err := PostgresqlStatementInfo(query); !errors.Is(err, ErrStatementTooLarge) {
+		t.Errorf("PostgresqlStatementInfo() error = %v, want %v", err, ErrStatementTooLarge)
+	}
+	if _, err := SqliteStatementInfo(query); !errors.Is(err, ErrStatementTooLarge) {
+		t.Errorf("SqliteStatementInfo() error = %v, want %v", err, ErrStatementTooLarge)
+	}
+	if got := ParseFailureCounts().Oversized - before.Oversized; got != 3 {
+		t.Errorf("oversized count = %d, want 3", got)
+	}
+}
+
+func TestGuardRecoversFromPanics(t *testing.T) {
+	before := ParseFailureCounts()
+	_, err := guard("SELECT 1", func() (StatementInfo, error) {
+		panic("index out of range")
+	}, nil)
+	if !errors.Is(err, ErrParsePanic) {
+		t.Errorf("guard() error = %v, want %v", err, ErrParsePanic)
+	}
+	if got := ParseFailureCounts().Panics - before.Panics; got != 1 {
+		t.Errorf("panic count = %d, want 1", got)
+	}
+
+	withParseLimits(t, ParseLimits{})
+	if _, err := guard("SELECT 1", func() (StatementInfo, error) { panic("boom") }, nil); !errors.Is(err, ErrParsePanic) {
+		t.Errorf("guard() without timeout error = %v, want %v", err, ErrParsePanic)
+	}
+}
+
+func TestGuardAbandonsSlowParses(t *testing.T) {
+	withParseLimits(t, ParseLimits{Timeout: 10 * time.Millisecond})
+	before := ParseFailureCounts()
+	release := make(chan struct{})
+	defer close(release)
+
+	start := time.Now()
+	_, err := guard("SELECT 1", func() (StatementInfo, error) {
+		<-release
+		return StatementInfo{Normalized: "SELECT ?"}, nil
+	}, nil)
+	if !errors.Is(err, ErrParseTimeout) {
+		t.Errorf("guard() error = %v, want %v", err, ErrParseTimeout)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("guard() returned after %v", elapsed)
+	}
+	if got := ParseFailureCounts().Timeouts - before.Timeouts; got != 1 {
+		t.Errorf("timeout count = %d, want 1", got)
+	}
+}
+
+func TestGuardParsesSmallStatementsInline(t *testing.T) {
+	withParseLimits(t, ParseLimits{Timeout: time.Millisecond, TimeoutMinSize: 64})
+
+	info, err := guard("SELECT 1", func() (StatementInfo, error) {
+		time.Sleep(10 * time.Millisecond)
+		return StatementInfo{Normalized: "SELECT ?"}, nil
+	}, nil)
+	if err != nil || info.Normalized != "SELECT ?" {
+		t.Errorf("guard() = %+v, %v", info, err)
+	}
+	_, err = guard(strings.Repeat(" ", 64)+"SELECT 1", func() (StatementInfo, error) {
+		time.Sleep(10 * time.Millisecond)
+		return StatementInfo{}, nil
+	}, nil)
+	if !errors.Is(err, ErrParseTimeout) {
+		t.Errorf("guard() error = %v, want %v", err, ErrParseTimeout)
+	}
+}
+
+func TestGuardPassesResults(t *testing.T) {
+	info, err := guard("SELECT 1", func() (StatementInfo, error) {
+		return StatementInfo{Normalized: "SELECT ?"}, nil
+	}, nil)
+	if err != nil || info.Normalized != "SELECT ?" {
+		t.Errorf("guard() = %+v, %v", info, err)
+	}
+}
+
+func TestGuardFallsBackWhenOverloaded(t *testing.T) {
+	withParseLimits(t, ParseLimits{Timeout: time.Second, MaxInFlight: 1})
+	before := ParseFailureCounts()
+
+	release := make(chan struct{})
+	defer close(release)
+	go guard("SELECT 1", func() (StatementInfo, error) {
+		<-release
+		return StatementInfo{}, nil
+	}, nil)
+	for inFlight.Load() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	info, err := MysqlStatementInfo("SELECT * FROM users WHERE name = 'bob'")
+	if err != nil || info.Normalized != "SELECT * FROM users WHERE name = ?" {
+		t.Errorf("MysqlStatementInfo() = %+v, %v, want the lexical redaction", info, err)
+	}
+	if _, err := guard("SELECT 1", func() (StatementInfo, error) { return StatementInfo{}, nil }, nil); !errors.Is(err, ErrParseOverloaded) {
+		t.Errorf("guard() error = %v, want %v", err, ErrParseOverloaded)
+	}
+	if got := ParseFailureCounts().Overloaded - before.Overloaded; got != 2 {
+		t.Errorf("overloaded count = %d, want 2", got)
+	}
+}
+
+func TestParseFailuresMetric(t *testing.T) {
+	reader := metricSdk.NewManualReader()
+	SetMeterProvider(metricSdk.NewMeterProvider(metricSdk.WithReader(reader)))
+	t.Cleanup(func() { SetMeterProvider(otel.GetMeterProvider()) })
+	withParseLimits(t, ParseLimits{MaxInputSize: 8})
+
+	if _, err := PostgresqlStatementInfo("SELECT * FROM users"); !errors.Is(err, ErrStatementTooLarge) {
+		t.Fatalf("PostgresqlStatementInfo() error = %v, want %v", err, ErrStatementTooLarge)
+	}
+	var rm metricdata.ResourceMetrics
+	if err := reader.Collect(context.Background(), &rm); err != nil {
+		t.Fatal(err)
+	}
+	if len(rm.ScopeMetrics) != 1 || len(rm.ScopeMetrics[0].Metrics) != 1 {
+		t.Fatalf("Collect() = %+v, want one metric", rm.ScopeMetrics)
+	}
+	m := rm.ScopeMetrics[0].Metrics[0]
+	sum, ok := m.Data.(metricdata.Sum[int64])
+	if m.Name != ParseFailuresMetric || !ok || len(sum.DataPoints) != 1 {
+		t.Fatalf("metric = %+v, want a %v sum", m, ParseFailuresMetric)
+	}
+	point := sum.DataPoints[0]
+	if reason, _ := point.Attributes.Value(ParseFailureReasonAttribute); point.Value != 1 || reason != attribute.StringValue("oversized") {
+		t.Errorf("data point = %+v, want 1 oversized", point)
+	}
+}
+
+func BenchmarkGuard(b *testing.B) {
+	query := "SELECT name FROM users WHERE id = 1 AND status = 'active'"
+	benchmarks := []struct {
+		name   string
+		limits ParseLimits
+	}{
+		{name: "unguarded", limits: ParseLimits{}},
+		{name: "inline", limits: DefaultParseLimits},
+		{name: "timeout", limits: ParseLimits{Timeout: DefaultParseLimits.Timeout}},
+	}
+	for _, bm := range benchmarks {
+		b.Run(bm.name, func(b *testing.B) {
+			SetParseLimits(bm.limits)
+			b.Cleanup(func() { SetParseLimits(DefaultParseLimits) })
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if _, err := PostgresqlStatementInfo(query); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
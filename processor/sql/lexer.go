@@ -0,0 +1,823 @@
+package sqlparser
+
+import (
+	"errors"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// The SQLite, SQL Server and Oracle normalizers work on tokens rather than on a
+// full AST: there is no maintained Go parser for those dialects, and redacting
+// literals, collapsing lists and naming the statement only needs lexical
+// knowledge of how each dialect quotes strings and identifiers and writes binds.
+
+var (
+	errUnterminated   = errors.New("sqlparser: unterminated quoted string or identifier")
+	errEmptyStatement = errors.New("sqlparser: empty statement")
+)
+
+type tokenKind int
+
+const (
+	tokenWord tokenKind = iota
+	tokenIdentifier
+	tokenLiteral
+	tokenPlaceholder
+	tokenPunctuation
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	// spaced reports whether the token was preceded by whitespace or a comment.
+	spaced bool
+	// offset is the index of the first rune of the token in the statement.
+	offset int
+}
+
+func (t token) is(kind tokenKind, text string) bool {
+	return t.kind == kind && strings.EqualFold(t.text, text)
+}
+
+// lexicalDialect describes the lexical rules of a dialect.
+type lexicalDialect struct {
+	// identifierQuotes maps opening identifier quotes to their closing quote.
+	identifierQuotes map[rune]rune
+	// placeholderPrefixes lists the characters starting a named or numbered bind,
+	// e.g. ':' for :1 and :name.
+	placeholderPrefixes string
+	// alternativeQuoting enables Oracle q'[...]' string literals.
+	alternativeQuoting bool
+	// moneyLiterals enables $12.50 money literals. In dialects where '$' starts
+	// binds, only numbers with a decimal point are money literals.
+	moneyLiterals bool
+	// dollarQuoting enables PostgreSQL $$...$$ and $tag$...$tag$ string literals.
+	dollarQuoting bool
+	// doubleQuotedStrings makes "..." a string literal rather than an identifier.
+	doubleQuotedStrings bool
+	// backslashEscapes enables backslash escapes in string literals.
+	backslashEscapes bool
+	// hashComments makes '#' start a comment running to the end of the line.
+	hashComments bool
+	// executeProcedures makes EXEC and EXECUTE call stored procedures.
+	executeProcedures bool
+	// quotedPlaceholders quotes the placeholders replacing literals, as in '?'.
+	quotedPlaceholders bool
+}
+
+var (
+	sqliteDialect = lexicalDialect{
+		identifierQuotes:    map[rune]rune{'"': '"', '`': '`', '[': ']'},
+		placeholderPrefixes: ":@$",
+		moneyLiterals:       true,
+	}
+	mssqlDialect = lexicalDialect{
+		identifierQuotes:    map[rune]rune{'"': '"', '[': ']'},
+		placeholderPrefixes: "@",
+		moneyLiterals:       true,
+		executeProcedures:   true,
+	}
+	oracleDialect = lexicalDialect{
+		identifierQuotes:    map[rune]rune{'"': '"'},
+		placeholderPrefixes: ":",
+		alternativeQuoting:  true,
+		moneyLiterals:       true,
+		executeProcedures:   true,
+	}
+	// mysqlLexicalDialect and postgresqlLexicalDialect handle the statements
+	// their parsers reject, such as CALL, and the splitting of scripts.
+	mysqlLexicalDialect = lexicalDialect{
+		identifierQuotes:    map[rune]rune{'`': '`'},
+		placeholderPrefixes: ":$",
+		moneyLiterals:       true,
+		hashComments:        true,
+		doubleQuotedStrings: true,
+		backslashEscapes:    true,
+	}
+	postgresqlLexicalDialect = lexicalDialect{
+		identifierQuotes:    map[rune]rune{'"': '"'},
+		placeholderPrefixes: "$",
+		moneyLiterals:       true,
+		dollarQuoting:       true,
+		quotedPlaceholders:  true,
+	}
+	// scriptDialect reads statements already normalized by any dialect.
+	scriptDialect = lexicalDialect{
+		identifierQuotes: map[rune]rune{'"': '"', '`': '`', '[': ']'},
+	}
+)
+
+func SqliteParse(dbStatementStr *string) (string, error) {
+	return lexicalParse(sqliteDialect, dbStatementStr)
+}
+
+func MssqlParse(dbStatementStr *string) (string, error) {
+	return lexicalParse(mssqlDialect, dbStatementStr)
+}
+
+func OracleParse(dbStatementStr *string) (string, error) {
+	return lexicalParse(oracleDialect, dbStatementStr)
+}
+
+// SqliteStatementInfo normalizes a SQLite statement and reports its operation and main table.
+func SqliteStatementInfo(query string) (StatementInfo, error) {
+	return sqliteDialect.statementInfo(query, RedactionPolicy{})
+}
+
+// MssqlStatementInfo normalizes a SQL Server statement and reports its operation and main table.
+func MssqlStatementInfo(query string) (StatementInfo, error) {
+	return mssqlDialect.statementInfo(query, RedactionPolicy{})
+}
+
+// OracleStatementInfo normalizes an Oracle statement and reports its operation and main table.
+func OracleStatementInfo(query string) (StatementInfo, error) {
+	return oracleDialect.statementInfo(query, RedactionPolicy{})
+}
+
+func lexicalParse(d lexicalDialect, dbStatementStr *string) (string, error) {
+	info, err := d.statementInfo(*dbStatementStr, RedactionPolicy{})
+	if err != nil {
+		return *dbStatementStr, err
+	}
+	return info.Normalized, nil
+}
+
+func lexicalStatementInfo(d lexicalDialect, query string, policy RedactionPolicy) (StatementInfo, error) {
+	tokens, err := d.normalize(query, policy)
+	if err != nil {
+		return StatementInfo{}, err
+	}
+	return StatementInfo{
+		Normalized: renderTokens(tokens),
+		Operation:  tokensOperation(tokens),
+		Table:      tokensTable(tokens),
+		Procedure:  d.tokensProcedure(tokens),
+	}, nil
+}
+
+func (d lexicalDialect) normalize(statement string, policy RedactionPolicy) ([]token, error) {
+	tokens, err := d.tokenize(statement)
+	if err != nil {
+		return nil, err
+	}
+	if len(tokens) == 0 {
+		return nil, errEmptyStatement
+	}
+	tokens = foldSigns(tokens)
+	for i := range tokens {
+		if isBooleanLiteral(tokens, i) {
+			tokens[i].kind = tokenLiteral
+		}
+	}
+	kept := tokensKeptLiterals(tokens, policy)
+	var binds []string
+	for _, t := range tokens {
+		if t.kind == tokenPlaceholder {
+			binds = append(binds, t.text)
+		}
+	}
+	placeholders := policy.placeholdersAfter(binds)
+	for i := range tokens {
+		if tokens[i].kind == tokenLiteral && !kept[i] {
+			tokens[i].text = placeholders.next()
+			if d.quotedPlaceholders && policy.Placeholder != NumberedPlaceholder {
+				tokens[i].text = "'" + tokens[i].text + "'"
+			}
+		}
+	}
+	return collapseTokenLists(tokens), nil
+}
+
+func (d lexicalDialect) tokenize(statement string) ([]token, error) {
+	var tokens []token
+	runes := []rune(statement)
+	spaced := false
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		next := rune(0)
+		if i+1 < len(runes) {
+			next = runes[i+1]
+		}
+		start := i
+		kind := tokenPunctuation
+		switch {
+		case unicode.IsSpace(r):
+			spaced = true
+			i++
+			continue
+		case r == '-' && next == '-':
+			for i < len(runes) && runes[i] != '\n' {
+				i++
+			}
+			spaced = true
+			continue
+		case d.hashComments && r == '#':
+			for i < len(runes) && runes[i] != '\n' {
+				i++
+			}
+			spaced = true
+			continue
+		case r == '/' && next == '*':
+			i += 2
+			for i+1 < len(runes) && !(runes[i] == '*' && runes[i+1] == '/') {
+				i++
+			}
+			if i+1 >= len(runes) {
+				return nil, errUnterminated
+			}
+			i += 2
+			spaced = true
+			continue
+		case r == '\'', d.doubleQuotedStrings && r == '"':
+			end, err := d.skipString(runes, i)
+			if err != nil {
+				return nil, err
+			}
+			i, kind = end, tokenLiteral
+		case strings.ContainsRune(stringPrefixes, r) && next == '\'':
+			end, err := d.skipPrefixedString(runes, i)
+			if err != nil {
+				return nil, err
+			}
+			i, kind = end, tokenLiteral
+		case d.alternativeQuoting && isAlternativeQuote(runes, i):
+			end, err := skipAlternativeQuote(runes, i)
+			if err != nil {
+				return nil, err
+			}
+			i, kind = end, tokenLiteral
+		case r == '[' && len(tokens) > 0 && tokens[len(tokens)-1].is(tokenWord, "array"):
+			// ARRAY[1, 2] is an array constructor rather than a bracketed identifier
+			i++
+		case d.identifierQuotes[r] != 0:
+			closing := d.identifierQuotes[r]
+			i++
+			for {
+				if i >= len(runes) {
+					return nil, errUnterminated
+				}
+				if runes[i] == closing {
+					// a doubled closing quote is an escaped one
+					if i+1 < len(runes) && runes[i+1] == closing {
+						i += 2
+						continue
+					}
+					i++
+					break
+				}
+				i++
+			}
+			kind = tokenIdentifier
+		case unicode.IsDigit(r), r == '.' && unicode.IsDigit(next):
+			i, kind = skipNumber(runes, i), tokenLiteral
+		case d.dollarQuoting && isDollarQuote(runes, i):
+			end, err := skipDollarQuote(runes, i)
+			if err != nil {
+				return nil, err
+			}
+			i, kind = end, tokenLiteral
+		case d.isMoneyLiteral(runes, i):
+			i, kind = skipNumber(runes, i+1), tokenLiteral
+		case r == '?':
+			i++
+			for i < len(runes) && unicode.IsDigit(runes[i]) {
+				i++
+			}
+			kind = tokenPlaceholder
+		case strings.ContainsRune(d.placeholderPrefixes, r) && isWordRune(next) && !(r == '@' && next == '@'):
+			i = skipWord(runes, i+1)
+			kind = tokenPlaceholder
+		case isWordRune(r) || r == '#' || r == '@':
+			// '#' starts SQL Server temporary tables, "@@" its system functions
+			i = skipWord(runes, i+1)
+			kind = tokenWord
+		default:
+			i++
+		}
+		tokens = append(tokens, token{kind: kind, text: string(runes[start:i]), spaced: spaced, offset: start})
+		spaced = false
+	}
+	return tokens, nil
+}
+
+// isWordRune reports whether r can be part of a keyword or an unquoted
+// identifier. Like the MySQL and PostgreSQL scanners, any non-ASCII character
+// but spaces is accepted.
+func isWordRune(r rune) bool {
+	return r >= utf8.RuneSelf && !unicode.IsSpace(r) || unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' || r == '$'
+}
+
+func skipWord(runes []rune, i int) int {
+	for i < len(runes) && (isWordRune(runes[i]) || runes[i] == '#' || runes[i] == '@') {
+		i++
+	}
+	return i
+}
+
+func skipNumber(runes []rune, i int) int {
+	if i+1 < len(runes) && runes[i] == '0' && (runes[i+1] == 'x' || runes[i+1] == 'X') {
+		i += 2
+		for i < len(runes) && (unicode.IsDigit(runes[i]) || strings.ContainsRune("abcdefABCDEF", runes[i])) {
+			i++
+		}
+		return i
+	}
+	for i < len(runes) && (unicode.IsDigit(runes[i]) || runes[i] == '.') {
+		i++
+	}
+	if i < len(runes) && (runes[i] == 'e' || runes[i] == 'E') {
+		j := i + 1
+		if j < len(runes) && (runes[j] == '+' || runes[j] == '-') {
+			j++
+		}
+		if j < len(runes) && unicode.IsDigit(runes[j]) {
+			i = j
+			for i < len(runes) && unicode.IsDigit(runes[i]) {
+				i++
+			}
+		}
+	}
+	// a suffix, like the d and f of Oracle 10d and 2f, is part of the number
+	for i < len(runes) && isWordRune(runes[i]) {
+		i++
+	}
+	return i
+}
+
+// stringPrefixes start the N'...' national, X'...' and B'...' binary and
+// E'...' escape string literals, whose prefix is part of the literal.
+const stringPrefixes = "nNxXbBeE"
+
+// skipPrefixedString returns the index just past the string literal whose
+// prefix is at start.
+func (d lexicalDialect) skipPrefixedString(runes []rune, start int) (int, error) {
+	if runes[start] == 'e' || runes[start] == 'E' {
+		d.backslashEscapes = true
+	}
+	return d.skipString(runes, start+1)
+}
+
+// foldSigns makes the sign of a number part of the literal, as in -1 or
+// = - 2, unless it follows an operand, as in qty - 1, where it is an operator.
+func foldSigns(tokens []token) []token {
+	folded := make([]token, 0, len(tokens))
+	for i := 0; i < len(tokens); i++ {
+		t := tokens[i]
+		if (t.is(tokenPunctuation, "-") || t.is(tokenPunctuation, "+")) && i+1 < len(tokens) && isNumber(tokens[i+1]) &&
+			(len(folded) == 0 || !isOperand(folded[len(folded)-1])) {
+			t.kind, t.text = tokenLiteral, t.text+tokens[i+1].text
+			i++
+		}
+		folded = append(folded, t)
+	}
+	return folded
+}
+
+// isBooleanLiteral reports whether the token at i is a TRUE or FALSE
+// literal, rather than the operand of IS TRUE or IS NOT FALSE, which the
+// MySQL and PostgreSQL parsers keep.
+func isBooleanLiteral(tokens []token, i int) bool {
+	if !tokens[i].is(tokenWord, "true") && !tokens[i].is(tokenWord, "false") {
+		return false
+	}
+	j := skipNot(tokens, i-1)
+	return j < 0 || !tokens[j].is(tokenWord, "is")
+}
+
+func isNumber(t token) bool {
+	return t.kind == tokenLiteral && (unicode.IsDigit(rune(t.text[0])) || t.text[0] == '.' || t.text[0] == '$')
+}
+
+// expressionKeywords are the keywords an expression, and so a signed number,
+// can follow.
+var expressionKeywords = map[string]bool{
+	"select": true, "distinct": true, "where": true, "having": true, "on": true, "set": true, "values": true,
+	"and": true, "or": true, "not": true, "is": true, "in": true, "like": true, "between": true,
+	"case": true, "when": true, "then": true, "else": true, "by": true, "top": true, "limit": true, "offset": true, "return": true,
+}
+
+// isOperand reports whether t ends an operand, after which a sign is a binary
+// operator.
+func isOperand(t token) bool {
+	switch t.kind {
+	case tokenLiteral, tokenPlaceholder, tokenIdentifier:
+		return true
+	case tokenWord:
+		return !expressionKeywords[strings.ToLower(t.text)]
+	default:
+		return t.text == ")" || t.text == "]"
+	}
+}
+
+// skipString returns the index just past the quoted string starting at start,
+// where a doubled quote is an escaped one.
+func (d lexicalDialect) skipString(runes []rune, start int) (int, error) {
+	quote := runes[start]
+	for i := start + 1; i < len(runes); i++ {
+		if d.backslashEscapes && runes[i] == '\\' {
+			i++
+			continue
+		}
+		if runes[i] == quote {
+			if i+1 < len(runes) && runes[i+1] == quote {
+				i++
+				continue
+			}
+			return i + 1, nil
+		}
+	}
+	return 0, errUnterminated
+}
+
+// isAlternativeQuote reports whether an Oracle q'<delimiter>...<delimiter>' or
+// nq'...' literal starts at i.
+func isAlternativeQuote(runes []rune, i int) bool {
+	if i+2 < len(runes) && (runes[i] == 'n' || runes[i] == 'N') {
+		i++
+	}
+	return i+2 < len(runes) && (runes[i] == 'q' || runes[i] == 'Q') && runes[i+1] == '\''
+}
+
+func (d lexicalDialect) isMoneyLiteral(runes []rune, i int) bool {
+	if !d.moneyLiterals || runes[i] != '$' || i+1 >= len(runes) {
+		return false
+	}
+	if !unicode.IsDigit(runes[i+1]) && runes[i+1] != '.' {
+		return false
+	}
+	if !strings.ContainsRune(d.placeholderPrefixes, '$') {
+		return true
+	}
+	// $1 is a bind, $1.50 an amount
+	end := skipNumber(runes, i+1)
+	return strings.ContainsRune(string(runes[i+1:end]), '.')
+}
+
+// isDollarQuote reports whether a $$ or $tag$ string delimiter starts at i.
+func isDollarQuote(runes []rune, i int) bool {
+	return dollarQuoteEnd(runes, i) > 0
+}
+
+// dollarQuoteEnd returns the index just past the $tag$ delimiter starting at
+// start, or 0. Tags cannot start with a digit, so that $1 remains a bind.
+func dollarQuoteEnd(runes []rune, start int) int {
+	if runes[start] != '$' {
+		return 0
+	}
+	for i := start + 1; i < len(runes); i++ {
+		switch r := runes[i]; {
+		case r == '$':
+			return i + 1
+		case unicode.IsLetter(r), r == '_', unicode.IsDigit(r) && i > start+1:
+		default:
+			return 0
+		}
+	}
+	return 0
+}
+
+func skipDollarQuote(runes []rune, start int) (int, error) {
+	end := dollarQuoteEnd(runes, start)
+	delimiter := string(runes[start:end])
+	body := string(runes[end:])
+	if j := strings.Index(body, delimiter); j >= 0 {
+		return end + len([]rune(body[:j])) + len([]rune(delimiter)), nil
+	}
+	return 0, errUnterminated
+}
+
+var alternativeQuoteClosers = map[rune]rune{'[': ']', '{': '}', '(': ')', '<': '>'}
+
+func skipAlternativeQuote(runes []rune, start int) (int, error) {
+	i := start
+	if runes[i] == 'n' || runes[i] == 'N' {
+		i++
+	}
+	opening := runes[i+2]
+	closing, ok := alternativeQuoteClosers[opening]
+	if !ok {
+		closing = opening
+	}
+	for j := i + 3; j+1 < len(runes); j++ {
+		if runes[j] == closing && runes[j+1] == '\'' {
+			return j + 2, nil
+		}
+	}
+	return 0, errUnterminated
+}
+
+// collapseTokenLists keeps the first element of IN lists made of literals and
+// placeholders, and the first row of multi-row VALUES clauses.
+func collapseTokenLists(tokens []token) []token {
+	collapsed := make([]token, 0, len(tokens))
+	for i := 0; i < len(tokens); i++ {
+		collapsed = append(collapsed, tokens[i])
+		switch {
+		case tokens[i].is(tokenWord, "in") && i+1 < len(tokens) && tokens[i+1].is(tokenPunctuation, "("):
+			end, ok := scalarListEnd(tokens, i+2, ")")
+			if ok && end > i+3 {
+				collapsed = append(collapsed, tokens[i+1], tokens[i+2], tokens[end])
+				i = end
+			}
+		case tokens[i].is(tokenWord, "array") && i+1 < len(tokens) && tokens[i+1].is(tokenPunctuation, "["):
+			end, ok := scalarListEnd(tokens, i+2, "]")
+			if ok && end > i+3 {
+				collapsed = append(collapsed, tokens[i+1], tokens[i+2], tokens[end])
+				i = end
+			}
+		case tokens[i].is(tokenWord, "values"):
+			end := groupEnd(tokens, i+1)
+			if end < 0 {
+				continue
+			}
+			collapsed = append(collapsed, tokens[i+1:end+1]...)
+			i = end
+			for i+2 < len(tokens) && tokens[i+1].is(tokenPunctuation, ",") && tokens[i+2].is(tokenPunctuation, "(") {
+				next := groupEnd(tokens, i+2)
+				if next < 0 {
+					break
+				}
+				i = next
+			}
+		}
+	}
+	return collapsed
+}
+
+// scalarListEnd returns the index of the closing ")" or "]" of a list of
+// literals and placeholders starting at start.
+func scalarListEnd(tokens []token, start int, closing string) (int, bool) {
+	for i := start; i < len(tokens); i += 2 {
+		if tokens[i].kind != tokenLiteral && tokens[i].kind != tokenPlaceholder {
+			return 0, false
+		}
+		if i+1 >= len(tokens) {
+			return 0, false
+		}
+		if tokens[i+1].is(tokenPunctuation, closing) {
+			return i + 1, true
+		}
+		if !tokens[i+1].is(tokenPunctuation, ",") {
+			return 0, false
+		}
+	}
+	return 0, false
+}
+
+// groupEnd returns the index of the ")" matching the "(" at start, or -1.
+func groupEnd(tokens []token, start int) int {
+	if start >= len(tokens) || !tokens[start].is(tokenPunctuation, "(") {
+		return -1
+	}
+	depth := 0
+	for i := start; i < len(tokens); i++ {
+		switch {
+		case tokens[i].is(tokenPunctuation, "("):
+			depth++
+		case tokens[i].is(tokenPunctuation, ")"):
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// renderTokens joins tokens with single spaces where the statement had
+// whitespace, always after commas, and around redacted literals that would
+// otherwise merge with their neighbours, like a number followed by a string.
+func renderTokens(tokens []token) string {
+	var b strings.Builder
+	for i, t := range tokens {
+		if i > 0 {
+			previous := tokens[i-1]
+			switch {
+			case t.is(tokenPunctuation, ","), t.is(tokenPunctuation, ")"), t.is(tokenPunctuation, ";"):
+			case previous.is(tokenPunctuation, "("):
+			case previous.is(tokenPunctuation, ","):
+				b.WriteByte(' ')
+			case t.spaced:
+				b.WriteByte(' ')
+			case previous.kind == tokenLiteral && t.kind != tokenPunctuation,
+				t.kind == tokenLiteral && previous.kind != tokenPunctuation:
+				b.WriteByte(' ')
+			}
+		}
+		b.WriteString(t.text)
+	}
+	return b.String()
+}
+
+func tokensOperation(tokens []token) string {
+	if tokens[0].kind != tokenWord {
+		return ""
+	}
+	operation := strings.ToUpper(tokens[0].text)
+	if operation != "WITH" {
+		return operation
+	}
+	// the operation of a common table expression is the first verb outside parentheses
+	depth := 0
+	for _, t := range tokens[1:] {
+		switch {
+		case t.is(tokenPunctuation, "("):
+			depth++
+		case t.is(tokenPunctuation, ")"):
+			depth--
+		case depth == 0 && t.kind == tokenWord:
+			switch verb := strings.ToUpper(t.text); verb {
+			case "SELECT", "INSERT", "UPDATE", "DELETE", "MERGE":
+				return verb
+			}
+		}
+	}
+	return operation
+}
+
+func tokensTable(tokens []token) string {
+	for i, t := range tokens {
+		if t.kind != tokenWord {
+			continue
+		}
+		switch strings.ToUpper(t.text) {
+		case "FROM", "INTO", "UPDATE", "JOIN", "TABLE":
+		default:
+			continue
+		}
+		if name := qualifiedName(tokens, i+1); name != "" {
+			return name
+		}
+	}
+	return ""
+}
+
+// qualifiedName returns the unquoted, possibly qualified, name starting at start.
+func qualifiedName(tokens []token, start int) string {
+	var parts []string
+	for j := start; j < len(tokens); j += 2 {
+		if tokens[j].kind != tokenWord && tokens[j].kind != tokenIdentifier {
+			break
+		}
+		parts = append(parts, unquoteIdentifier(tokens[j].text))
+		if j+1 >= len(tokens) || !tokens[j+1].is(tokenPunctuation, ".") {
+			break
+		}
+	}
+	return strings.Join(parts, ".")
+}
+
+func unquoteIdentifier(identifier string) string {
+	if len(identifier) >= 2 {
+		switch identifier[0] {
+		case '"', '`', '[':
+			return identifier[1 : len(identifier)-1]
+		}
+	}
+	return identifier
+}
+
+func (d lexicalDialect) statementInfo(query string, policy RedactionPolicy) (StatementInfo, error) {
+	// the lexer is linear, so it runs inline as its own fallback
+	describe := func() (StatementInfo, error) {
+		return lexicalStatementInfo(d, query, policy)
+	}
+	return guard(query, describe, describe)
+}
+
+// tokensKeptLiterals returns the indexes of the literals compared to, assigned
+// to or inserted into the columns the policy keeps.
+func tokensKeptLiterals(tokens []token, policy RedactionPolicy) map[int]bool {
+	kept := map[int]bool{}
+	if len(policy.AllowedColumns) == 0 {
+		return kept
+	}
+	for column, literals := range tokensInsertedLiterals(tokens) {
+		if policy.keepsColumn(column) {
+			for _, i := range literals {
+				kept[i] = true
+			}
+		}
+	}
+	for i, t := range tokens {
+		if t.kind == tokenLiteral && policy.keepsColumn(tokensLiteralColumn(tokens, i)) {
+			kept[i] = true
+		}
+	}
+	return kept
+}
+
+// tokensLiteralColumn returns the column the literal at i is compared to, in
+// col = 'x', col LIKE 'x', col IN ('x', 'y') and col BETWEEN 1 AND 2.
+func tokensLiteralColumn(tokens []token, i int) string {
+	j := i - 1
+	for j >= 0 && (tokens[j].is(tokenPunctuation, ",") || tokens[j].kind == tokenLiteral || tokens[j].kind == tokenPlaceholder) {
+		j--
+	}
+	if j > 0 && tokens[j].is(tokenPunctuation, "(") && tokens[j-1].is(tokenWord, "in") {
+		return columnBefore(tokens, skipNot(tokens, j-2))
+	}
+	j = i - 1
+	operator := false
+	for j >= 0 && tokens[j].kind == tokenPunctuation && strings.ContainsAny(tokens[j].text, "=<>!") {
+		j--
+		operator = true
+	}
+	switch {
+	case operator:
+	case j >= 0 && (tokens[j].is(tokenWord, "like") || tokens[j].is(tokenWord, "between")):
+		j = skipNot(tokens, j-1)
+	case j >= 2 && tokens[j].is(tokenWord, "and") && tokens[j-1].kind == tokenLiteral && tokens[j-2].is(tokenWord, "between"):
+		j = skipNot(tokens, j-3)
+	default:
+		return ""
+	}
+	return columnBefore(tokens, j)
+}
+
+func skipNot(tokens []token, i int) int {
+	if i >= 0 && tokens[i].is(tokenWord, "not") {
+		return i - 1
+	}
+	return i
+}
+
+// columnBefore returns the unquoted, possibly qualified, name ending at end.
+func columnBefore(tokens []token, end int) string {
+	start := end
+	for start >= 0 && (tokens[start].kind == tokenWord || tokens[start].kind == tokenIdentifier) {
+		if start < 2 || !tokens[start-1].is(tokenPunctuation, ".") {
+			break
+		}
+		start -= 2
+	}
+	if start < 0 || (tokens[start].kind != tokenWord && tokens[start].kind != tokenIdentifier) {
+		return ""
+	}
+	return qualifiedName(tokens, start)
+}
+
+// tokensInsertedLiterals maps the columns of INSERT INTO table (columns) VALUES
+// statements, qualified by the table, to the indexes of the literals inserted
+// into them.
+func tokensInsertedLiterals(tokens []token) map[string][]int {
+	inserted := map[string][]int{}
+	for i, t := range tokens {
+		if !t.is(tokenWord, "into") {
+			continue
+		}
+		table := qualifiedName(tokens, i+1)
+		j := i + 1
+		for j < len(tokens) && !tokens[j].is(tokenPunctuation, "(") {
+			j++
+		}
+		end := groupEnd(tokens, j)
+		if table == "" || end < 0 || end+1 >= len(tokens) || !tokens[end+1].is(tokenWord, "values") {
+			continue
+		}
+		var columns []string
+		for _, element := range groupElements(tokens, j, end) {
+			columns = append(columns, table+"."+qualifiedName(tokens, element[0]))
+		}
+		for row := end + 2; row < len(tokens) && tokens[row].is(tokenPunctuation, "("); {
+			rowEnd := groupEnd(tokens, row)
+			if rowEnd < 0 {
+				break
+			}
+			for k, element := range groupElements(tokens, row, rowEnd) {
+				if k < len(columns) && element[1]-element[0] == 1 && tokens[element[0]].kind == tokenLiteral {
+					inserted[columns[k]] = append(inserted[columns[k]], element[0])
+				}
+			}
+			row = rowEnd + 1
+			if row < len(tokens) && tokens[row].is(tokenPunctuation, ",") {
+				row++
+			}
+		}
+	}
+	return inserted
+}
+
+// groupElements returns the [start, end) token ranges of the comma separated
+// elements of the group between the parentheses at start and end.
+func groupElements(tokens []token, start int, end int) [][2]int {
+	var elements [][2]int
+	depth := 0
+	elementStart := start + 1
+	for i := start + 1; i < end; i++ {
+		switch {
+		case tokens[i].is(tokenPunctuation, "("):
+			depth++
+		case tokens[i].is(tokenPunctuation, ")"):
+			depth--
+		case depth == 0 && tokens[i].is(tokenPunctuation, ","):
+			elements = append(elements, [2]int{elementStart, i})
+			elementStart = i + 1
+		}
+	}
+	if elementStart < end {
+		elements = append(elements, [2]int{elementStart, end})
+	}
+	return elements
+}
@@ -0,0 +1,141 @@
+package sqlparser
+
+import (
+	"testing"
+)
+
+func TestLexicalParse(t *testing.T) {
+	tests := []struct {
+		name     string
+		parse    func(*string) (string, error)
+		input    string
+		expected string
+		wantErr  bool
+	}{
+		{
+			name:     "sqlite select",
+			parse:    SqliteParse,
+			input:    "SELECT * FROM users WHERE id = 123 AND name = 'O''Brien'",
+			expected: "SELECT * FROM users WHERE id = ? AND name = ?",
+		},
+		{
+			name:     "sqlite binds and blob",
+			parse:    SqliteParse,
+			input:    "INSERT INTO files (id, owner, data) VALUES (?1, :owner, X'DEADBEEF')",
+			expected: "INSERT INTO files (id, owner, data) VALUES (?1, :owner, ?)",
+		},
+		{
+			name:     "sqlite multi row insert",
+			parse:    SqliteParse,
+			input:    "INSERT INTO t (a, b) VALUES (1, 'x'), (2, 'y'), (3, 'z') RETURNING a",
+			expected: "INSERT INTO t (a, b) VALUES (?, ?) RETURNING a",
+		},
+		{
+			name:     "mssql parameters and bracketed identifiers",
+			parse:    MssqlParse,
+			input:    "SELECT TOP 10 [u].[name] FROM [dbo].[users] AS [u] WHERE [u].[id] = @p1 AND [u].[email] = N'alice@example.com'",
+			expected: "SELECT TOP ? [u].[name] FROM [dbo].[users] AS [u] WHERE [u].[id] = @p1 AND [u].[email] = ?",
+		},
+		{
+			name:     "mssql money, temp tables and system functions",
+			parse:    MssqlParse,
+			input:    "UPDATE #orders SET total = $100.50 WHERE id IN (1, 2, 3); SELECT @@ROWCOUNT",
+			expected: "UPDATE #orders SET total = ? WHERE id IN (?); SELECT @@ROWCOUNT",
+		},
+		{
+			name:     "oracle binds and alternative quoting",
+			parse:    OracleParse,
+			input:    "SELECT * FROM hr.employees WHERE first_name = q'[It's]' AND dept_id = :1 AND manager = :mgr",
+			expected: "SELECT * FROM hr.employees WHERE first_name = ? AND dept_id = :1 AND manager = :mgr",
+		},
+		{
+			name:     "oracle comments and scientific notation",
+			parse:    OracleParse,
+			input:    "SELECT /*+ INDEX(e) */ salary * 1.5E3 FROM employees e -- bonus\nWHERE hired > DATE '2020-01-01'",
+			expected: "SELECT salary * ? FROM employees e WHERE hired > DATE ?",
+		},
+		{
+			name:     "oracle numeric suffixes",
+			parse:    OracleParse,
+			input:    "SELECT * FROM measures WHERE ratio > 10d AND weight < 2.5f AND flags = X'ab'",
+			expected: "SELECT * FROM measures WHERE ratio > ? AND weight < ? AND flags = ?",
+		},
+		{
+			name:     "mssql signed numbers and binary operators",
+			parse:    MssqlParse,
+			input:    "UPDATE stock SET qty = qty - 1, floor = -5 WHERE delta IN (-1, + 2, 3) AND [level]-1 > - 3",
+			expected: "UPDATE stock SET qty = qty - ?, floor = ? WHERE delta IN (?) AND [level]-? > ?",
+		},
+		{
+			name:     "sqlite array constructor",
+			parse:    SqliteParse,
+			input:    "SELECT * FROM users WHERE id = ANY(ARRAY[1, 2, 3]) AND [group] = 'admins'",
+			expected: "SELECT * FROM users WHERE id = ANY(ARRAY[?]) AND [group] = ?",
+		},
+		{
+			name:    "unterminated string",
+			parse:   OracleParse,
+			input:   "SELECT * FROM users WHERE name = 'Alice",
+			wantErr: true,
+		},
+		{
+			name:    "unterminated identifier",
+			parse:   MssqlParse,
+			input:   "SELECT * FROM [users",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.parse(&tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("lexicalParse() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !tt.wantErr && got != tt.expected {
+				t.Errorf("lexicalParse() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestLexicalStatementInfo(t *testing.T) {
+	tests := []struct {
+		name     string
+		describe func(string) (StatementInfo, error)
+		input    string
+		expected StatementInfo
+	}{
+		{
+			name:     "mssql select",
+			describe: MssqlStatementInfo,
+			input:    "SELECT [name] FROM [dbo].[users] WHERE [id] = @p1",
+			expected: StatementInfo{Normalized: "SELECT [name] FROM [dbo].[users] WHERE [id] = @p1", Operation: "SELECT", Table: "dbo.users"},
+		},
+		{
+			name:     "oracle common table expression",
+			describe: OracleStatementInfo,
+			input:    "WITH recent AS (SELECT id FROM orders WHERE total > 5) DELETE FROM archive WHERE id IN (SELECT id FROM recent)",
+			expected: StatementInfo{Normalized: "WITH recent AS (SELECT id FROM orders WHERE total > ?) DELETE FROM archive WHERE id IN (SELECT id FROM recent)", Operation: "DELETE", Table: "orders"},
+		},
+		{
+			name:     "sqlite insert",
+			describe: SqliteStatementInfo,
+			input:    "insert into \"events\" (kind) values ('login')",
+			expected: StatementInfo{Normalized: "insert into \"events\" (kind) values (?)", Operation: "INSERT", Table: "events"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.describe(tt.input)
+			if err != nil {
+				t.Fatalf("statementInfo() error = %v", err)
+			}
+			if got != tt.expected {
+				t.Errorf("statementInfo() = %+v, want %+v", got, tt.expected)
+			}
+		})
+	}
+}
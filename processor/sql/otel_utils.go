@@ -2,26 +2,81 @@ package sqlparser
 
 import (
 	"context"
+
+	"go.opentelemetry.io/otel/attribute"
 )
 
 func MysqlSpanFormatter(ctx context.Context, method string, query string) string {
-	if query != "" {
-		parsed, err := MysqlParse(&query)
-		if err != nil {
-			return method
-		}
-		return parsed
-	}
-	return method
+	return spanFormatter(ctx, MysqlParse, method, query)
 }
 
 func PostgresqlSpanFormatter(ctx context.Context, method string, query string) string {
+	return spanFormatter(ctx, PostgresqlParse, method, query)
+}
+
+func SqliteSpanFormatter(ctx context.Context, method string, query string) string {
+	return spanFormatter(ctx, SqliteParse, method, query)
+}
+
+func MssqlSpanFormatter(ctx context.Context, method string, query string) string {
+	return spanFormatter(ctx, MssqlParse, method, query)
+}
+
+func OracleSpanFormatter(ctx context.Context, method string, query string) string {
+	return spanFormatter(ctx, OracleParse, method, query)
+}
+
+// spanFormatter names a span after its normalized query, unless ctx sets a
+// query name or disables normalization.
+func spanFormatter(ctx context.Context, parse func(*string) (string, error), method string, query string) string {
+	if name, ok := ContextSpanName(ctx, method); ok {
+		return name
+	}
 	if query != "" {
-		parsed, err := PostgresqlParse(&query)
+		parsed, err := parse(&query)
 		if err != nil {
 			return method
 		}
-		return parsed
+		return SpanName(parsed)
 	}
 	return method
 }
+
+// MysqlFingerprintAttributes returns the db.query.fingerprint attribute for a MySQL
+// query, to be attached to the span named by MysqlSpanFormatter.
+func MysqlFingerprintAttributes(ctx context.Context, method string, query string) []attribute.KeyValue {
+	return fingerprintAttributes(ctx, MysqlParse, method, query)
+}
+
+// PostgresqlFingerprintAttributes returns the db.query.fingerprint attribute for a
+// PostgreSQL query, to be attached to the span named by PostgresqlSpanFormatter.
+func PostgresqlFingerprintAttributes(ctx context.Context, method string, query string) []attribute.KeyValue {
+	return fingerprintAttributes(ctx, PostgresqlParse, method, query)
+}
+
+// SqliteFingerprintAttributes returns the db.query.fingerprint attribute for a
+// SQLite query, to be attached to the span named by SqliteSpanFormatter.
+func SqliteFingerprintAttributes(ctx context.Context, method string, query string) []attribute.KeyValue {
+	return fingerprintAttributes(ctx, SqliteParse, method, query)
+}
+
+// MssqlFingerprintAttributes returns the db.query.fingerprint attribute for a
+// SQL Server query, to be attached to the span named by MssqlSpanFormatter.
+func MssqlFingerprintAttributes(ctx context.Context, method string, query string) []attribute.KeyValue {
+	return fingerprintAttributes(ctx, MssqlParse, method, query)
+}
+
+// OracleFingerprintAttributes returns the db.query.fingerprint attribute for an
+// Oracle query, to be attached to the span named by OracleSpanFormatter.
+func OracleFingerprintAttributes(ctx context.Context, method string, query string) []attribute.KeyValue {
+	return fingerprintAttributes(ctx, OracleParse, method, query)
+}
+
+// fingerprintAttributes fingerprints the normalized query, unless ctx sets a
+// query name or disables normalization, which both skip parsing.
+func fingerprintAttributes(ctx context.Context, parse func(*string) (string, error), method string, query string) []attribute.KeyValue {
+	if _, ok := ContextSpanName(ctx, method); ok || query == "" {
+		return nil
+	}
+	return []attribute.KeyValue{attribute.String(QueryFingerprintAttribute, fingerprint(parse, query))}
+}
@@ -0,0 +1,252 @@
+package sqlparser
+
+import (
+	"reflect"
+
+	"github.com/auxten/postgresql-parser/pkg/sql/sem/tree"
+)
+
+// RedactionMode controls which literals PostgresqlParseWithMode replaces with a placeholder.
+type RedactionMode int
+
+const (
+	// RedactAll replaces every constant in the statement: VALUES rows, SET clauses,
+	// LIMIT/OFFSET, function arguments, CASE branches, arrays and comparisons.
+	RedactAll RedactionMode = iota
+	// RedactComparisons only replaces the non-column side of comparison expressions.
+	RedactComparisons
+)
+
+var treePkgPath = reflect.TypeOf(tree.Select{}).PkgPath()
+
+// constant returns the next placeholder as a PostgreSQL expression.
+func (p *placeholders) constant() tree.Expr {
+	text := p.next()
+	if p.style == NumberedPlaceholder {
+		return &tree.Placeholder{Idx: tree.PlaceholderIdx(p.count - 1)}
+	}
+	return tree.NewStrVal(text)
+}
+
+func isRedactableConstant(expr tree.Expr) bool {
+	switch expr.(type) {
+	case *tree.Placeholder:
+		// binds carry no data in the statement and tell statements apart
+		return false
+	case *tree.NumVal, *tree.StrVal:
+		return true
+	case tree.Datum:
+		// NULL carries no data and changes the meaning of IS NULL when replaced
+		return expr != tree.DNull
+	}
+	return false
+}
+
+// redactConstants replaces every constant reachable from node, except the ones
+// bound to columns the policy keeps.
+func redactConstants(node tree.NodeFormatter, policy RedactionPolicy) {
+	kept := postgresqlKeptConstants(node, policy)
+	var binds []string
+	if policy.Placeholder == NumberedPlaceholder {
+		walkTree(node, func(n interface{}) tree.Expr {
+			if placeholder, ok := n.(*tree.Placeholder); ok {
+				binds = append(binds, placeholder.String())
+			}
+			return nil
+		})
+	}
+	placeholders := policy.placeholdersAfter(binds)
+	walkTree(node, func(n interface{}) tree.Expr {
+		if expr, ok := n.(tree.Expr); ok && isRedactableConstant(expr) && !kept[expr] {
+			return placeholders.constant()
+		}
+		return nil
+	})
+}
+
+// postgresqlKeptConstants returns the constants compared to, assigned to or
+// inserted into the columns the policy keeps, see markConstants.
+func postgresqlKeptConstants(node tree.NodeFormatter, policy RedactionPolicy) map[tree.Expr]bool {
+	kept := map[tree.Expr]bool{}
+	if len(policy.AllowedColumns) == 0 {
+		return kept
+	}
+	keep := func(column string, exprs ...tree.Expr) {
+		if !policy.keepsColumn(column) {
+			return
+		}
+		for _, expr := range exprs {
+			markConstants(kept, expr)
+		}
+	}
+	// statements are not visited by walkTree when they are the root node or
+	// pointers in slices, like the SET clauses of an UPDATE
+	inspect := func(n interface{}) {
+		switch n := n.(type) {
+		case *tree.ComparisonExpr:
+			if column := postgresqlColumnName(n.Left); column != "" {
+				keep(column, n.Right)
+			} else if column := postgresqlColumnName(n.Right); column != "" {
+				keep(column, n.Left)
+			}
+		case *tree.RangeCond:
+			keep(postgresqlColumnName(n.Left), n.From, n.To)
+		case *tree.Update:
+			table := postgresqlTableExprName(n.Table)
+			for _, expr := range n.Exprs {
+				if len(expr.Names) == 1 {
+					keep(table+"."+expr.Names[0].String(), expr.Expr)
+				}
+			}
+		case *tree.Insert:
+			if n.Rows == nil {
+				break
+			}
+			values, ok := n.Rows.Select.(*tree.ValuesClause)
+			if !ok {
+				break
+			}
+			table := postgresqlTableExprName(n.Table)
+			for _, row := range values.Rows {
+				for i, expr := range row {
+					if i < len(n.Columns) {
+						keep(table+"."+n.Columns[i].String(), expr)
+					}
+				}
+			}
+		}
+	}
+	inspect(node)
+	walkTree(node, func(n interface{}) tree.Expr {
+		inspect(n)
+		return nil
+	})
+	return kept
+}
+
+func postgresqlColumnName(expr tree.Expr) string {
+	switch e := expr.(type) {
+	case *tree.UnresolvedName:
+		return e.String()
+	case *tree.ColumnItem:
+		return e.String()
+	}
+	return ""
+}
+
+// markConstants marks the constants bound directly to a column: expr itself, or
+// the elements of a list of constants. Constants nested in function calls,
+// operators or subqueries are not bound to the column, and are redacted.
+func markConstants(kept map[tree.Expr]bool, expr tree.Expr) {
+	switch e := expr.(type) {
+	case *tree.ParenExpr:
+		markConstants(kept, e.Expr)
+	case *tree.Tuple:
+		for _, element := range e.Exprs {
+			if isRedactableConstant(element) {
+				kept[element] = true
+			}
+		}
+	case *tree.Array:
+		for _, element := range e.Exprs {
+			if isRedactableConstant(element) {
+				kept[element] = true
+			}
+		}
+	default:
+		if expr != nil && isRedactableConstant(expr) {
+			kept[expr] = true
+		}
+	}
+}
+
+// collapseLists keeps a single row of multi-row VALUES clauses and a single
+// element of IN lists and ANY/SOME/ALL arrays, so the statement text does not
+// depend on the batch size.
+func collapseLists(node tree.NodeFormatter) {
+	walkTree(node, func(n interface{}) tree.Expr {
+		switch n := n.(type) {
+		case *tree.ValuesClause:
+			if len(n.Rows) > 1 {
+				n.Rows = n.Rows[:1]
+			}
+		case *tree.ComparisonExpr:
+			switch n.Operator {
+			case tree.In, tree.NotIn:
+				// a one-element tuple prints as "(x,)", a parenthesized expression as "(x)"
+				if tuple, ok := n.Right.(*tree.Tuple); ok && len(tuple.Exprs) > 0 {
+					n.Right = &tree.ParenExpr{Expr: tuple.Exprs[0]}
+				}
+			case tree.Any, tree.Some, tree.All:
+				collapseListExpr(n.Right)
+			}
+		}
+		return nil
+	})
+}
+
+func collapseListExpr(expr tree.Expr) {
+	switch e := expr.(type) {
+	case *tree.ParenExpr:
+		collapseListExpr(e.Expr)
+	case *tree.Array:
+		if len(e.Exprs) > 1 {
+			e.Exprs = e.Exprs[:1]
+		}
+	}
+}
+
+// walkTree calls visit for every node held in an interface field of the AST
+// (expressions, statements, table expressions...). visit may mutate the node in
+// place; when it returns a non-nil expression, that expression replaces the node
+// and its subtree is not visited. The parser's own walkers only cover a subset of
+// the AST (no INSERT, UPDATE or LIMIT), so the tree is traversed through
+// reflection instead, restricted to the tree package types so that type metadata
+// and operator tables are never entered.
+func walkTree(node tree.NodeFormatter, visit func(node interface{}) tree.Expr) {
+	walkValue(reflect.ValueOf(node), visit, map[uintptr]bool{})
+}
+
+func walkValue(v reflect.Value, visit func(node interface{}) tree.Expr, seen map[uintptr]bool) {
+	switch v.Kind() {
+	case reflect.Interface:
+		if v.IsNil() {
+			return
+		}
+		if replacement := visit(v.Interface()); replacement != nil {
+			r := reflect.ValueOf(replacement)
+			if v.CanSet() && r.Type().AssignableTo(v.Type()) {
+				v.Set(r)
+			}
+			return
+		}
+		walkValue(v.Elem(), visit, seen)
+	case reflect.Ptr:
+		if v.IsNil() || v.Type().Elem().PkgPath() != treePkgPath {
+			return
+		}
+		if seen[v.Pointer()] {
+			return
+		}
+		seen[v.Pointer()] = true
+		walkValue(v.Elem(), visit, seen)
+	case reflect.Struct:
+		if v.Type().PkgPath() != treePkgPath {
+			return
+		}
+		for i := 0; i < v.NumField(); i++ {
+			if v.Type().Field(i).IsExported() {
+				walkValue(v.Field(i), visit, seen)
+			}
+		}
+	case reflect.Slice, reflect.Array:
+		switch v.Type().Elem().Kind() {
+		case reflect.Interface, reflect.Ptr, reflect.Struct, reflect.Slice:
+		default:
+			return
+		}
+		for i := 0; i < v.Len(); i++ {
+			walkValue(v.Index(i), visit, seen)
+		}
+	}
+}
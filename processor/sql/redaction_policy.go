@@ -0,0 +1,190 @@
+package sqlparser
+
+import (
+	"context"
+	"strconv"
+	"strings"
+)
+
+// PlaceholderStyle is the text replacing redacted literals.
+type PlaceholderStyle int
+
+const (
+	// QuestionMarkPlaceholder replaces literals with ?, quoted as '?' by PostgreSQL.
+	QuestionMarkPlaceholder PlaceholderStyle = iota
+	// NumberedPlaceholder replaces literals with $1, $2... in order of
+	// appearance, numbered after the binds of the statement.
+	NumberedPlaceholder
+	// RedactedPlaceholder replaces literals with <redacted>, quoted by PostgreSQL.
+	RedactedPlaceholder
+)
+
+// RedactionPolicy tunes which literals are redacted from statements and how,
+// to balance security and debuggability per service. The zero value redacts
+// every literal with ?, as the Parse functions do.
+type RedactionPolicy struct {
+	// AllowedColumns lists the columns whose literals are kept, e.g. status or
+	// type: literals compared to them, assigned to them or inserted into them.
+	// Names are matched case-insensitively, either unqualified or as table.column.
+	AllowedColumns []string
+	// DeniedColumns lists the columns whose literals are always redacted, even
+	// when they also match AllowedColumns, e.g. payments.type. A table.column
+	// entry also redacts the unqualified column of the same name, whatever its
+	// table.
+	DeniedColumns []string
+	// Placeholder is the style of redacted literals.
+	Placeholder PlaceholderStyle
+	// MaxLength caps the length, in characters, of normalized statements, which
+	// are truncated with a trailing "...". Zero means no limit.
+	MaxLength int
+}
+
+// keepsColumn reports whether the literals bound to column are kept.
+func (p RedactionPolicy) keepsColumn(column string) bool {
+	if column == "" || len(p.AllowedColumns) == 0 {
+		return false
+	}
+	return !deniesColumn(p.DeniedColumns, column) && matchesColumn(p.AllowedColumns, column)
+}
+
+var identifierQuotes = strings.NewReplacer("\"", "", "`", "", "[", "", "]", "")
+
+// columnNames returns column, unquoted and lower-cased, and its unqualified name.
+func columnNames(column string) (qualified string, name string) {
+	column = strings.ToLower(identifierQuotes.Replace(column))
+	if i := strings.LastIndexByte(column, '.'); i >= 0 {
+		return column, column[i+1:]
+	}
+	return column, column
+}
+
+func matchesColumn(columns []string, column string) bool {
+	column, name := columnNames(column)
+	for _, c := range columns {
+		c = strings.ToLower(c)
+		if c == column || c == name {
+			return true
+		}
+	}
+	return false
+}
+
+// deniesColumn is matchesColumn failing closed: as the table of an unqualified
+// column is not resolved, a table.column entry also denies the unqualified
+// column of the same name.
+func deniesColumn(columns []string, column string) bool {
+	if matchesColumn(columns, column) {
+		return true
+	}
+	qualified, name := columnNames(column)
+	if qualified != name {
+		return false
+	}
+	for _, c := range columns {
+		if _, deniedName := columnNames(c); deniedName == name {
+			return true
+		}
+	}
+	return false
+}
+
+// truncate caps statement to MaxLength characters.
+func (p RedactionPolicy) truncate(statement string) string {
+	if p.MaxLength <= 0 {
+		return statement
+	}
+	runes := []rune(statement)
+	if len(runes) <= p.MaxLength {
+		return statement
+	}
+	if p.MaxLength <= 3 {
+		return string(runes[:p.MaxLength])
+	}
+	return string(runes[:p.MaxLength-3]) + "..."
+}
+
+// placeholders hands out the placeholders of a statement in order.
+type placeholders struct {
+	style PlaceholderStyle
+	count int
+}
+
+// placeholdersAfter numbers placeholders after the binds of the statement,
+// past both their count and their highest number, so that a $n placeholder
+// never reads as one of them.
+func (p RedactionPolicy) placeholdersAfter(binds []string) *placeholders {
+	count := len(binds)
+	for _, bind := range binds {
+		if n, err := strconv.Atoi(strings.TrimLeft(bind, "?$:@")); err == nil && n > count {
+			count = n
+		}
+	}
+	return &placeholders{style: p.Placeholder, count: count}
+}
+
+func (p *placeholders) next() string {
+	p.count++
+	switch p.style {
+	case NumberedPlaceholder:
+		return "$" + strconv.Itoa(p.count)
+	case RedactedPlaceholder:
+		return "<redacted>"
+	}
+	return "?"
+}
+
+// StatementInfo normalizes a statement of the dialect registered for a
+// db.system value according to the policy.
+func (p RedactionPolicy) StatementInfo(dbSystem string, query string) (StatementInfo, error) {
+	describe, ok := dialectDescriber(dbSystem)
+	if !ok {
+		return StatementInfo{}, errUnknownDialect
+	}
+	info, err := describe(query, p)
+	if err != nil {
+		return StatementInfo{}, err
+	}
+	info.Normalized = p.truncate(info.Normalized)
+	return info, nil
+}
+
+// SpanFormatter returns the span formatter of the dialect registered for a
+// db.system value, applying the policy. It names spans after the method for
+// unknown dialects, and honors the overrides of WithQueryName and
+// WithoutNormalization.
+func (p RedactionPolicy) SpanFormatter(dbSystem string) func(ctx context.Context, method string, query string) string {
+	describe, ok := dialectDescriber(dbSystem)
+	return func(ctx context.Context, method string, query string) string {
+		if name, overridden := ContextSpanName(ctx, method); overridden {
+			return name
+		}
+		if !ok || query == "" {
+			return method
+		}
+		info, err := describe(query, p)
+		if err != nil {
+			return method
+		}
+		return p.truncate(SpanName(info.Normalized))
+	}
+}
+
+func (p RedactionPolicy) MysqlSpanFormatter(ctx context.Context, method string, query string) string {
+	return p.SpanFormatter("mysql")(ctx, method, query)
+}
+
+func (p RedactionPolicy) PostgresqlSpanFormatter(ctx context.Context, method string, query string) string {
+	return p.SpanFormatter("postgresql")(ctx, method, query)
+}
+
+func (p RedactionPolicy) SqliteSpanFormatter(ctx context.Context, method string, query string) string {
+	return p.SpanFormatter("sqlite")(ctx, method, query)
+}
+
+func (p RedactionPolicy) MssqlSpanFormatter(ctx context.Context, method string, query string) string {
+	return p.SpanFormatter("mssql")(ctx, method, query)
+}
+
+func (p RedactionPolicy) OracleSpanFormatter(ctx context.Context, method string, query string) string {
+	return p.SpanFormatter("oracle")(ctx, method, query)
+}
@@ -0,0 +1,208 @@
+package sqlparser
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestRedactionPolicy(t *testing.T) {
+	statusPolicy := RedactionPolicy{AllowedColumns: []string{"status", "type"}, DeniedColumns: []string{"payments.type"}}
+	tests := []struct {
+		name     string
+		policy   RedactionPolicy
+		dbSystem string
+		input    string
+		expected string
+	}{
+		{
+			name:     "mysql allowed comparison",
+			policy:   statusPolicy,
+			dbSystem: "mysql",
+			input:    "SELECT * FROM orders WHERE status = 'shipped' AND customer = 'bob'",
+			expected: "select * from orders where `status` = 'shipped' and customer = ?",
+		},
+		{
+			name:     "mysql allowed insert",
+			policy:   statusPolicy,
+			dbSystem: "mysql",
+			input:    "INSERT INTO orders (id, status) VALUES (7, 'new')",
+			expected: "insert into orders(id, `status`) values (?, 'new')",
+		},
+		{
+			name:     "mysql denied column wins",
+			policy:   statusPolicy,
+			dbSystem: "mysql",
+			input:    "INSERT INTO payments (type, amount) VALUES ('card', 10)",
+			expected: "insert into payments(type, amount) values (?, ?)",
+		},
+		{
+			name:     "postgresql allowed comparison and in list",
+			policy:   statusPolicy,
+			dbSystem: "postgresql",
+			input:    "SELECT * FROM orders WHERE status IN ('new', 'paid') AND total > 10",
+			expected: "SELECT * FROM orders WHERE (status IN ('new')) AND (total > '?')",
+		},
+		{
+			name:     "postgresql allowed update",
+			policy:   statusPolicy,
+			dbSystem: "postgresql",
+			input:    "UPDATE orders SET status = 'paid', note = 'call me' WHERE id = 3",
+			expected: "UPDATE orders SET status = 'paid', note = '?' WHERE id = '?'",
+		},
+		{
+			name:     "postgresql denied column wins",
+			policy:   statusPolicy,
+			dbSystem: "postgresql",
+			input:    "INSERT INTO payments (type) VALUES ('card')",
+			expected: "INSERT INTO payments(type) VALUES ('?')",
+		},
+		{
+			name:     "mssql allowed comparison",
+			policy:   statusPolicy,
+			dbSystem: "mssql",
+			input:    "SELECT * FROM [orders] WHERE [status] = N'new' AND id = 5",
+			expected: "SELECT * FROM [orders] WHERE [status] = N'new' AND id = ?",
+		},
+		{
+			name:     "sqlite allowed insert",
+			policy:   statusPolicy,
+			dbSystem: "sqlite",
+			input:    "INSERT INTO orders (id, status) VALUES (1, 'new'), (2, 'paid')",
+			expected: "INSERT INTO orders (id, status) VALUES (?, 'new')",
+		},
+		{
+			name:     "oracle allowed between and like",
+			policy:   RedactionPolicy{AllowedColumns: []string{"o.type"}},
+			dbSystem: "oracle",
+			input:    "SELECT * FROM orders o WHERE o.type LIKE 'ret%' AND o.total BETWEEN 1 AND 2",
+			expected: "SELECT * FROM orders o WHERE o.type LIKE 'ret%' AND o.total BETWEEN ? AND ?",
+		},
+		{
+			name:     "mysql numbered placeholders",
+			policy:   RedactionPolicy{Placeholder: NumberedPlaceholder},
+			dbSystem: "mysql",
+			input:    "SELECT * FROM users WHERE name = 'bob' AND age > 30",
+			expected: "select * from users where name = $1 and age > $2",
+		},
+		{
+			name:     "postgresql numbered placeholders",
+			policy:   RedactionPolicy{Placeholder: NumberedPlaceholder},
+			dbSystem: "postgresql",
+			input:    "SELECT * FROM users WHERE name = 'bob' AND age > 30",
+			expected: "SELECT * FROM users WHERE (name = $1) AND (age > $2)",
+		},
+		{
+			name:     "postgresql numbered placeholders after binds",
+			policy:   RedactionPolicy{Placeholder: NumberedPlaceholder},
+			dbSystem: "postgresql",
+			input:    "SELECT * FROM users WHERE a = $1 AND b = 5 AND c = $3",
+			expected: "SELECT * FROM users WHERE ((a = $1) AND (b = $4)) AND (c = $3)",
+		},
+		{
+			name:     "mysql numbered placeholders after binds",
+			policy:   RedactionPolicy{Placeholder: NumberedPlaceholder},
+			dbSystem: "mysql",
+			input:    "SELECT * FROM users WHERE a = ? AND b = 5 AND c = ?",
+			expected: "select * from users where a = ? and b = $3 and c = ?",
+		},
+		{
+			name:     "sqlite numbered placeholders after binds",
+			policy:   RedactionPolicy{Placeholder: NumberedPlaceholder},
+			dbSystem: "sqlite",
+			input:    "SELECT * FROM users WHERE a = ?2 AND b = 'x' AND c = :name",
+			expected: "SELECT * FROM users WHERE a = ?2 AND b = $3 AND c = :name",
+		},
+		{
+			name:     "postgresql redacted placeholders",
+			policy:   RedactionPolicy{Placeholder: RedactedPlaceholder},
+			dbSystem: "postgresql",
+			input:    "SELECT * FROM users WHERE name = 'bob'",
+			expected: "SELECT * FROM users WHERE name = '<redacted>'",
+		},
+		{
+			name:     "mssql redacted placeholders",
+			policy:   RedactionPolicy{Placeholder: RedactedPlaceholder},
+			dbSystem: "mssql",
+			input:    "SELECT * FROM users WHERE name = 'bob'",
+			expected: "SELECT * FROM users WHERE name = <redacted>",
+		},
+		{
+			name:     "max length",
+			policy:   RedactionPolicy{MaxLength: 20},
+			dbSystem: "mysql",
+			input:    "SELECT id, name, email FROM users WHERE id = 1",
+			expected: "select id, name, ...",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			info, err := tt.policy.StatementInfo(tt.dbSystem, tt.input)
+			if err != nil {
+				t.Fatalf("StatementInfo() error = %v", err)
+			}
+			if info.Normalized != tt.expected {
+				t.Errorf("StatementInfo() = %v, want %v", info.Normalized, tt.expected)
+			}
+		})
+	}
+}
+
+func TestRedactionPolicyDeniedColumns(t *testing.T) {
+	policy := RedactionPolicy{AllowedColumns: []string{"type"}, DeniedColumns: []string{"payments.type"}}
+	for _, dbSystem := range []string{"mysql", "postgresql", "sqlite", "mssql", "oracle"} {
+		for _, input := range []string{
+			"SELECT * FROM payments WHERE type = 'card'",
+			"UPDATE payments SET type = 'card' WHERE id = 1",
+			"INSERT INTO payments (type) VALUES ('card')",
+		} {
+			info, err := policy.StatementInfo(dbSystem, input)
+			if err != nil {
+				t.Fatalf("%s: StatementInfo() error = %v", dbSystem, err)
+			}
+			if strings.Contains(info.Normalized, "card") {
+				t.Errorf("%s: StatementInfo() = %v, want card redacted", dbSystem, info.Normalized)
+			}
+		}
+		info, err := policy.StatementInfo(dbSystem, "INSERT INTO orders (type) VALUES ('gift')")
+		if err != nil || !strings.Contains(info.Normalized, "gift") {
+			t.Errorf("%s: StatementInfo() = %v, %v, want the type of orders kept", dbSystem, info.Normalized, err)
+		}
+	}
+}
+
+func TestRedactionPolicyZeroValue(t *testing.T) {
+	for _, input := range []string{
+		"SELECT * FROM orders WHERE status = 'shipped'",
+		"INSERT INTO orders (id, status) VALUES (7, 'new'), (8, 'old')",
+		"UPDATE orders SET status = 'paid' WHERE id = $1",
+	} {
+		for _, dbSystem := range []string{"mysql", "postgresql", "sqlite", "mssql", "oracle"} {
+			parse, _ := DialectParser(dbSystem)
+			query := input
+			expected, err := parse(&query)
+			if err != nil {
+				continue
+			}
+			info, err := RedactionPolicy{}.StatementInfo(dbSystem, input)
+			if err != nil || info.Normalized != expected {
+				t.Errorf("%s: StatementInfo() = %v, %v, want %v", dbSystem, info.Normalized, err, expected)
+			}
+		}
+	}
+}
+
+func TestRedactionPolicySpanFormatter(t *testing.T) {
+	policy := RedactionPolicy{AllowedColumns: []string{"status"}, MaxLength: 40}
+	ctx := context.Background()
+	if got := policy.MysqlSpanFormatter(ctx, "query", "SELECT id FROM orders WHERE status = 'new'"); got != "select id from orders where `status` ..." {
+		t.Errorf("MysqlSpanFormatter() = %v", got)
+	}
+	if got := policy.PostgresqlSpanFormatter(ctx, "query", "SELECT * FROM"); got != "query" {
+		t.Errorf("PostgresqlSpanFormatter() = %v, want query", got)
+	}
+	if got := policy.SpanFormatter("cassandra")(ctx, "query", "SELECT 1"); got != "query" {
+		t.Errorf("SpanFormatter() = %v, want query", got)
+	}
+}
@@ -0,0 +1,120 @@
+package sqlparser
+
+import (
+	"fmt"
+	"strings"
+)
+
+// BatchSpanName names the span of a script made of several statements, whose
+// statements would otherwise make the span name as long and as diverse as the
+// scripts themselves.
+func BatchSpanName(count int) string {
+	return fmt.Sprintf("BATCH %d statements", count)
+}
+
+// SpanName returns the name of the span executing a normalized statement: the
+// statement itself, or BatchSpanName when it is a script of several statements.
+func SpanName(normalized string) string {
+	statements, err := scriptDialect.splitStatements(normalized)
+	if err != nil || len(statements) < 2 {
+		return normalized
+	}
+	return BatchSpanName(len(statements))
+}
+
+// splitStatements splits a script on the semicolons outside of literals,
+// identifiers and comments, dropping empty statements.
+func (d lexicalDialect) splitStatements(script string) ([]string, error) {
+	tokens, err := d.tokenize(script)
+	if err != nil {
+		return nil, err
+	}
+	runes := []rune(script)
+	var statements []string
+	start := 0
+	appendStatement := func(end int) {
+		if statement := strings.TrimSpace(string(runes[start:end])); statement != "" {
+			statements = append(statements, statement)
+		}
+	}
+	for _, t := range tokens {
+		if t.is(tokenPunctuation, ";") {
+			appendStatement(t.offset)
+			start = t.offset + 1
+		}
+	}
+	appendStatement(len(runes))
+	return statements, nil
+}
+
+// isProcedureCall reports whether statement calls a stored procedure.
+func (d lexicalDialect) isProcedureCall(statement string) bool {
+	tokens, err := d.tokenize(statement)
+	return err == nil && len(tokens) > 0 && d.tokensProcedure(tokens) != ""
+}
+
+// tokensProcedure returns the name of the procedure called by a CALL statement,
+// or by an EXEC or EXECUTE statement in dialects where they call procedures
+// rather than prepared statements.
+func (d lexicalDialect) tokensProcedure(tokens []token) string {
+	if tokens[0].kind != tokenWord {
+		return ""
+	}
+	switch strings.ToUpper(tokens[0].text) {
+	case "CALL":
+	case "EXEC", "EXECUTE":
+		if !d.executeProcedures {
+			return ""
+		}
+	default:
+		return ""
+	}
+	start := 1
+	// EXEC @status = dbo.procedure ...
+	if len(tokens) > 3 && tokens[1].kind == tokenPlaceholder && tokens[2].is(tokenPunctuation, "=") {
+		start = 3
+	}
+	return qualifiedName(tokens, start)
+}
+
+func containsProcedureCall(d lexicalDialect, statements []string) bool {
+	for _, statement := range statements {
+		if d.isProcedureCall(statement) {
+			return true
+		}
+	}
+	return false
+}
+
+// scriptInfo normalizes the statements of a script one by one, with
+// statementInfo or lexically for the procedure calls the dialect parser does
+// not support. The returned info describes the first statement, while its
+// Normalized field holds the whole normalized script.
+func scriptInfo(d lexicalDialect, statements []string, policy RedactionPolicy, statementInfo func(string) (StatementInfo, error)) (StatementInfo, error) {
+	var info StatementInfo
+	var normalized []string
+	for _, statement := range statements {
+		if strings.TrimSpace(statement) == "" {
+			continue
+		}
+		var statementResult StatementInfo
+		var err error
+		if d.isProcedureCall(statement) {
+			statementResult, err = lexicalStatementInfo(d, statement, policy)
+		} else {
+			statementResult, err = statementInfo(statement)
+		}
+		if err != nil {
+			return StatementInfo{}, err
+		}
+		if len(normalized) == 0 {
+			info = statementResult
+		}
+		normalized = append(normalized, statementResult.Normalized)
+	}
+	if len(normalized) == 0 {
+		return StatementInfo{}, errEmptyStatement
+	}
+	info.Normalized = strings.Join(normalized, "; ")
+	return info, nil
+}
@@ -0,0 +1,149 @@
+package sqlparser
+
+import (
+	"context"
+	"testing"
+)
+
+func TestScriptStatementInfo(t *testing.T) {
+	tests := []struct {
+		name     string
+		describe func(string) (StatementInfo, error)
+		input    string
+		expected StatementInfo
+	}{
+		{
+			name:     "mysql script",
+			describe: MysqlStatementInfo,
+			input:    "UPDATE users SET name = 'bob' WHERE id = 1; SELECT * FROM users WHERE id = 1;",
+			expected: StatementInfo{Normalized: "update users set name = ? where id = ?; select * from users where id = ?", Operation: "UPDATE", Table: "users"},
+		},
+		{
+			name:     "mysql semicolon inside a literal",
+			describe: MysqlStatementInfo,
+			input:    "INSERT INTO notes (body) VALUES ('a; b')",
+			expected: StatementInfo{Normalized: "insert into notes(body) values (?)", Operation: "INSERT", Table: "notes"},
+		},
+		{
+			name:     "mysql call",
+			describe: MysqlStatementInfo,
+			input:    "CALL shop.add_order(42, \"it\\\"s\", 'x', ?)",
+			expected: StatementInfo{Normalized: "CALL shop.add_order(?, ?, ?, ?)", Operation: "CALL", Procedure: "shop.add_order"},
+		},
+		{
+			name:     "mysql call in a script",
+			describe: MysqlStatementInfo,
+			input:    "CALL refresh_totals(7); SELECT total FROM totals WHERE id = 7",
+			expected: StatementInfo{Normalized: "CALL refresh_totals(?); select total from totals where id = ?", Operation: "CALL", Procedure: "refresh_totals"},
+		},
+		{
+			name:     "postgresql script",
+			describe: PostgresqlStatementInfo,
+			input:    "DELETE FROM carts WHERE id = 3; DELETE FROM orders WHERE id = 4",
+			expected: StatementInfo{Normalized: "DELETE FROM carts WHERE id = '?'; DELETE FROM orders WHERE id = '?'", Operation: "DELETE", Table: "carts"},
+		},
+		{
+			name:     "postgresql call",
+			describe: PostgresqlStatementInfo,
+			input:    "CALL transfer(1, 2, 100.5, $1)",
+			expected: StatementInfo{Normalized: "CALL transfer('?', '?', '?', $1)", Operation: "CALL", Procedure: "transfer"},
+		},
+		{
+			name:     "postgresql call in a script",
+			describe: PostgresqlStatementInfo,
+			input:    "SELECT balance FROM accounts WHERE id = 1; CALL audit('read')",
+			expected: StatementInfo{Normalized: "SELECT balance FROM accounts WHERE id = '?'; CALL audit('?')", Operation: "SELECT", Table: "accounts"},
+		},
+		{
+			name:     "mssql exec with named arguments",
+			describe: MssqlStatementInfo,
+			input:    "EXEC dbo.usp_add_user @name = N'bob', @age = 42",
+			expected: StatementInfo{Normalized: "EXEC dbo.usp_add_user @name = ?, @age = ?", Operation: "EXEC", Procedure: "dbo.usp_add_user"},
+		},
+		{
+			name:     "mssql exec with return status",
+			describe: MssqlStatementInfo,
+			input:    "EXECUTE @status = [dbo].[usp_purge] 30",
+			expected: StatementInfo{Normalized: "EXECUTE @status = [dbo].[usp_purge] ?", Operation: "EXECUTE", Procedure: "dbo.usp_purge"},
+		},
+		{
+			name:     "oracle call",
+			describe: OracleStatementInfo,
+			input:    "CALL billing.close_period(:1, 'Q1')",
+			expected: StatementInfo{Normalized: "CALL billing.close_period(:1, ?)", Operation: "CALL", Procedure: "billing.close_period"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.describe(tt.input)
+			if err != nil {
+				t.Fatalf("statementInfo() error = %v", err)
+			}
+			if got != tt.expected {
+				t.Errorf("statementInfo() = %+v, want %+v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestSpanName(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{input: "SELECT * FROM users WHERE id = ?", expected: "SELECT * FROM users WHERE id = ?"},
+		{input: "SELECT * FROM users WHERE id = ?;", expected: "SELECT * FROM users WHERE id = ?;"},
+		{input: "SELECT ?; SELECT ?; SELECT ?", expected: "BATCH 3 statements"},
+		{input: "SELECT * FROM \"a;b\" WHERE c = '?'", expected: "SELECT * FROM \"a;b\" WHERE c = '?'"},
+	}
+
+	for _, tt := range tests {
+		if got := SpanName(tt.input); got != tt.expected {
+			t.Errorf("SpanName(%q) = %v, want %v", tt.input, got, tt.expected)
+		}
+	}
+}
+
+func TestSpanFormatterNamesBatches(t *testing.T) {
+	ctx := context.Background()
+	tests := []struct {
+		name      string
+		formatter func(context.Context, string, string) string
+		input     string
+		expected  string
+	}{
+		{
+			name:      "mysql",
+			formatter: MysqlSpanFormatter,
+			input:     "SELECT 1; SELECT 2",
+			expected:  "BATCH 2 statements",
+		},
+		{
+			name:      "postgresql",
+			formatter: PostgresqlSpanFormatter,
+			input:     "BEGIN; UPDATE t SET a = 1; COMMIT",
+			expected:  "BATCH 3 statements",
+		},
+		{
+			name:      "mssql",
+			formatter: MssqlSpanFormatter,
+			input:     "SET NOCOUNT ON; EXEC dbo.usp_sync 1",
+			expected:  "BATCH 2 statements",
+		},
+		{
+			name:      "postgresql call",
+			formatter: PostgresqlSpanFormatter,
+			input:     "CALL refresh(42)",
+			expected:  "CALL refresh('?')",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.formatter(ctx, "query", tt.input); got != tt.expected {
+				t.Errorf("formatter() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,170 @@
+package sqlparser
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/attribute"
+	traceSdk "go.opentelemetry.io/otel/sdk/trace"
+)
+
+const (
+	DBSystemAttribute          = "db.system"
+	DBSystemNameAttribute      = "db.system.name"
+	DBStatementAttribute       = "db.statement"
+	DBQueryTextAttribute       = "db.query.text"
+	DBOperationAttribute       = "db.operation"
+	DBTableAttribute           = "db.sql.table"
+	DBRowsAffectedAttribute    = "db.rows_affected"
+	DBStoredProcedureAttribute = "db.stored_procedure.name"
+)
+
+// SpanProcessor normalizes the db.statement and db.query.text attributes of
+// ended database spans before handing them to the next processor, so spans from
+// any instrumentation (pgx, gorm, sqlx, ent...) are exported without literals.
+// Statements that fail to normalize have their literals redacted lexically, or
+// are dropped if even that fails.
+type SpanProcessor struct {
+	next         traceSdk.SpanProcessor
+	renameSpans  bool
+	fingerprint  bool
+	sqlCommenter bool
+	policy       RedactionPolicy
+}
+
+type SpanProcessorOption func(*SpanProcessor)
+
+// WithSpanRename also replaces the span name with the normalized statement,
+// like MysqlSpanFormatter and PostgresqlSpanFormatter do.
+func WithSpanRename() SpanProcessorOption {
+	return func(p *SpanProcessor) {
+		p.renameSpans = true
+	}
+}
+
+// WithFingerprint adds the db.query.fingerprint attribute to normalized spans.
+func WithFingerprint() SpanProcessorOption {
+	return func(p *SpanProcessor) {
+		p.fingerprint = true
+	}
+}
+
+// WithSqlCommenterAttributes adds the sqlcommenter tags of the statement, which
+// normalization drops along with the other comments, as db.sqlcommenter.*
+// attributes.
+func WithSqlCommenterAttributes() SpanProcessorOption {
+	return func(p *SpanProcessor) {
+		p.sqlCommenter = true
+	}
+}
+
+// WithRedactionPolicy normalizes the db.statement of spans according to
+// policy, e.g. to keep the literals of some columns or to number placeholders,
+// rather than replacing every literal with '?'.
+func WithRedactionPolicy(policy RedactionPolicy) SpanProcessorOption {
+	return func(p *SpanProcessor) {
+		p.policy = policy
+	}
+}
+
+// NewSpanProcessor wraps next, typically a batch span processor, with statement
+// normalization.
+func NewSpanProcessor(next traceSdk.SpanProcessor, options ...SpanProcessorOption) *SpanProcessor {
+	if next == nil {
+		panic("span processor is null")
+	}
+	p := &SpanProcessor{next: next}
+	for _, option := range options {
+		option(p)
+	}
+	return p
+}
+
+func (p *SpanProcessor) OnStart(parent context.Context, s traceSdk.ReadWriteSpan) {
+	p.next.OnStart(parent, s)
+}
+
+func (p *SpanProcessor) OnEnd(s traceSdk.ReadOnlySpan) {
+	p.next.OnEnd(p.normalize(s))
+}
+
+func (p *SpanProcessor) Shutdown(ctx context.Context) error {
+	return p.next.Shutdown(ctx)
+}
+
+func (p *SpanProcessor) ForceFlush(ctx context.Context) error {
+	return p.next.ForceFlush(ctx)
+}
+
+func (p *SpanProcessor) normalize(s traceSdk.ReadOnlySpan) traceSdk.ReadOnlySpan {
+	attributes := s.Attributes()
+	dbSystem := ""
+	for _, kv := range attributes {
+		if kv.Key == DBSystemAttribute || kv.Key == DBSystemNameAttribute && dbSystem == "" {
+			dbSystem = kv.Value.AsString()
+		}
+	}
+	if _, ok := dialectDescriber(dbSystem); !ok {
+		return s
+	}
+
+	normalized := make([]attribute.KeyValue, 0, len(attributes)+1)
+	statement := ""
+	found := false
+	var tags []attribute.KeyValue
+	for _, kv := range attributes {
+		if kv.Key == DBStatementAttribute || kv.Key == DBQueryTextAttribute {
+			found = true
+			query := kv.Value.AsString()
+			if p.sqlCommenter && tags == nil {
+				tags = SqlCommenterAttributes(context.Background(), "", query)
+			}
+			redacted, ok := p.redact(dbSystem, query)
+			if !ok {
+				continue
+			}
+			kv = attribute.String(string(kv.Key), redacted)
+			statement = redacted
+		}
+		normalized = append(normalized, kv)
+	}
+	if !found {
+		return s
+	}
+	if p.fingerprint && statement != "" {
+		normalized = append(normalized, attribute.String(QueryFingerprintAttribute, Fingerprint(statement)))
+	}
+	normalized = append(normalized, tags...)
+	name := s.Name()
+	if p.renameSpans && statement != "" {
+		name = p.policy.truncate(SpanName(statement))
+	}
+	return normalizedSpan{ReadOnlySpan: s, name: name, attributes: normalized}
+}
+
+// redact normalizes query, falling back to redacting its literals lexically,
+// and returns false if both fail.
+func (p *SpanProcessor) redact(dbSystem string, query string) (string, bool) {
+	if info, err := p.policy.StatementInfo(dbSystem, query); err == nil {
+		return info.Normalized, true
+	}
+	redacted, err := redactLexically(dbSystem, query)
+	if err != nil {
+		return "", false
+	}
+	return p.policy.truncate(redacted), true
+}
+
+// normalizedSpan overrides the name and attributes of an ended span.
+type normalizedSpan struct {
+	traceSdk.ReadOnlySpan
+	name       string
+	attributes []attribute.KeyValue
+}
+
+func (s normalizedSpan) Name() string {
+	return s.name
+}
+
+func (s normalizedSpan) Attributes() []attribute.KeyValue {
+	return s.attributes
+}
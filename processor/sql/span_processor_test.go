@@ -0,0 +1,134 @@
+package sqlparser
+
+import (
+	"context"
+	"testing"
+
+	"go.opentelemetry.io/otel/attribute"
+	traceSdk "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func attributeValue(span traceSdk.ReadOnlySpan, key string) (string, bool) {
+	for _, kv := range span.Attributes() {
+		if string(kv.Key) == key {
+			return kv.Value.AsString(), true
+		}
+	}
+	return "", false
+}
+
+func TestSpanProcessor(t *testing.T) {
+	tests := []struct {
+		name               string
+		options            []SpanProcessorOption
+		attributes         []attribute.KeyValue
+		expectedName       string
+		expectedKey        string
+		expectedStatement  string
+		expectsFingerprint bool
+	}{
+		{
+			name: "postgresql db.statement",
+			attributes: []attribute.KeyValue{
+				attribute.String(DBSystemAttribute, "postgresql"),
+				attribute.String(DBStatementAttribute, "SELECT * FROM users WHERE id = 123"),
+			},
+			expectedName:      "query",
+			expectedKey:       DBStatementAttribute,
+			expectedStatement: "SELECT * FROM users WHERE id = '?'",
+		},
+		{
+			name:    "mysql db.query.text with rename and fingerprint",
+			options: []SpanProcessorOption{WithSpanRename(), WithFingerprint()},
+			attributes: []attribute.KeyValue{
+				attribute.String(DBSystemAttribute, "mysql"),
+				attribute.String(DBQueryTextAttribute, "SELECT * FROM users WHERE name = 'Alice'"),
+			},
+			expectedName:       "select * from users where name = ?",
+			expectedKey:        DBQueryTextAttribute,
+			expectedStatement:  "select * from users where name = ?",
+			expectsFingerprint: true,
+		},
+		{
+			name: "unknown db.system is left untouched",
+			attributes: []attribute.KeyValue{
+				attribute.String(DBSystemAttribute, "cassandra"),
+				attribute.String(DBStatementAttribute, "SELECT * FROM users WHERE id = 123"),
+			},
+			expectedName:      "query",
+			expectedKey:       DBStatementAttribute,
+			expectedStatement: "SELECT * FROM users WHERE id = 123",
+		},
+		{
+			name:    "unparsable statement is redacted lexically",
+			options: []SpanProcessorOption{WithSpanRename()},
+			attributes: []attribute.KeyValue{
+				attribute.String(DBSystemAttribute, "postgresql"),
+				attribute.String(DBStatementAttribute, "SELECT * FROM users WHERE name = 'Alice' AND"),
+			},
+			expectedName:      "SELECT * FROM users WHERE name = '?' AND",
+			expectedKey:       DBStatementAttribute,
+			expectedStatement: "SELECT * FROM users WHERE name = '?' AND",
+		},
+		{
+			name: "db.system.name",
+			attributes: []attribute.KeyValue{
+				attribute.String(DBSystemNameAttribute, "microsoft.sql_server"),
+				attribute.String(DBQueryTextAttribute, "SELECT * FROM users WHERE name = N'Alice'"),
+			},
+			expectedName:      "query",
+			expectedKey:       DBQueryTextAttribute,
+			expectedStatement: "SELECT * FROM users WHERE name = ?",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := tracetest.NewSpanRecorder()
+			provider := traceSdk.NewTracerProvider(traceSdk.WithSpanProcessor(NewSpanProcessor(recorder, tt.options...)))
+			_, span := provider.Tracer("test").Start(context.Background(), "query")
+			span.SetAttributes(tt.attributes...)
+			span.End()
+
+			ended := recorder.Ended()
+			if len(ended) != 1 {
+				t.Fatalf("ended spans = %d, want 1", len(ended))
+			}
+			if ended[0].Name() != tt.expectedName {
+				t.Errorf("span name = %v, want %v", ended[0].Name(), tt.expectedName)
+			}
+			if statement, _ := attributeValue(ended[0], tt.expectedKey); statement != tt.expectedStatement {
+				t.Errorf("%v = %v, want %v", tt.expectedKey, statement, tt.expectedStatement)
+			}
+			if _, ok := attributeValue(ended[0], QueryFingerprintAttribute); ok != tt.expectsFingerprint {
+				t.Errorf("fingerprint attribute present = %v, want %v", ok, tt.expectsFingerprint)
+			}
+		})
+	}
+}
+
+func TestSpanProcessor_FailsClosed(t *testing.T) {
+	defer SetParseLimits(DefaultParseLimits)
+	SetParseLimits(ParseLimits{MaxInputSize: 16, Timeout: DefaultParseLimits.Timeout})
+	recorder := tracetest.NewSpanRecorder()
+	provider := traceSdk.NewTracerProvider(traceSdk.WithSpanProcessor(NewSpanProcessor(recorder, WithSpanRename(), WithFingerprint())))
+
+	_, span := provider.Tracer("test").Start(context.Background(), "SELECT 'Alice")
+	span.SetAttributes(attribute.String(DBSystemAttribute, "mysql"), attribute.String(DBStatementAttribute, "SELECT 'Alice"))
+	span.End()
+	_, span = provider.Tracer("test").Start(context.Background(), "query")
+	span.SetAttributes(attribute.String(DBSystemAttribute, "mysql"), attribute.String(DBStatementAttribute, "SELECT * FROM users WHERE name = 'Alice'"))
+	span.End()
+
+	unterminated := recorder.Ended()[0]
+	if _, ok := attributeValue(unterminated, DBStatementAttribute); ok {
+		t.Errorf("unterminated statement was exported")
+	}
+	if _, ok := attributeValue(unterminated, QueryFingerprintAttribute); ok {
+		t.Errorf("dropped statement was fingerprinted")
+	}
+	if statement, _ := attributeValue(recorder.Ended()[1], DBStatementAttribute); statement != "SELECT * FROM users WHERE name = ?" {
+		t.Errorf("oversized statement = %v, want it redacted lexically", statement)
+	}
+}
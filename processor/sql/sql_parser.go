@@ -5,27 +5,60 @@ import (
 	"github.com/auxten/postgresql-parser/pkg/sql/sem/tree"
 	"github.com/auxten/postgresql-parser/pkg/walk"
 	mysqlparser "github.com/xwb1989/sqlparser"
-	"regexp"
-	"strings"
 )
 
 func MysqlParse(dbStatementStr *string) (string, error) {
-	replaceDollarInsideValues(dbStatementStr)
-	stmt, err := mysqlparser.Parse(*dbStatementStr)
+	info, err := MysqlStatementInfo(*dbStatementStr)
 	if err != nil {
 		return *dbStatementStr, err
 	}
-	blueprint := mysqlReplaceValuesWithPlaceholder(stmt)
-	blueprintStr := mysqlparser.String(blueprint)
-	return blueprintStr, nil
+	return info.Normalized, nil
+}
+
+func mysqlNormalize(dbStatementStr string, policy RedactionPolicy) (mysqlparser.Statement, error) {
+	rewritten, binds, err := mysqlBinds(dbStatementStr)
+	if err != nil {
+		return nil, err
+	}
+	stmt, err := mysqlparser.Parse(rewritten)
+	if err != nil {
+		return nil, err
+	}
+	return mysqlReplaceValuesWithPlaceholder(stmt, binds, policy), nil
 }
 
 func PostgresqlParse(dbStatementStr *string) (string, error) {
-	replaceDollarInsideValues(dbStatementStr)
-	stmts, err := parser.Parse(*dbStatementStr)
+	return PostgresqlParseWithMode(dbStatementStr, RedactAll)
+}
+
+// PostgresqlParseWithMode parses a PostgreSQL statement and replaces its literals
+// according to mode.
+func PostgresqlParseWithMode(dbStatementStr *string, mode RedactionMode) (string, error) {
+	info, err := postgresqlScriptInfo(*dbStatementStr, mode, RedactionPolicy{})
 	if err != nil {
 		return *dbStatementStr, err
 	}
+	return info.Normalized, nil
+}
+
+func postgresqlNormalize(dbStatementStr string, mode RedactionMode, policy RedactionPolicy) (parser.Statements, error) {
+	rewritten, err := postgresqlLexicalDialect.rewriteStatement(dbStatementStr, nil)
+	if err != nil {
+		return nil, err
+	}
+	stmts, err := parser.Parse(rewritten)
+	if err != nil {
+		return nil, err
+	}
+	for _, stmt := range stmts {
+		collapseLists(stmt.AST)
+	}
+	if mode == RedactAll {
+		for _, stmt := range stmts {
+			redactConstants(stmt.AST, policy)
+		}
+		return stmts, nil
+	}
 	w := &walk.AstWalker{
 		Fn: func(_ any, node any) (stop bool) {
 			if n, ok := node.(*tree.ComparisonExpr); ok {
@@ -33,6 +66,11 @@ func PostgresqlParse(dbStatementStr *string) (string, error) {
 				_, leftIsUnresolved := n.Left.(*tree.UnresolvedName)
 				_, rightIsColumn := n.Right.(*tree.ColumnItem)
 				_, rightIsUnresolved := n.Right.(*tree.UnresolvedName)
+				_, leftIsPlaceholder := n.Left.(*tree.Placeholder)
+				_, rightIsPlaceholder := n.Right.(*tree.Placeholder)
+				if leftIsPlaceholder || rightIsPlaceholder {
+					return false
+				}
 				if leftIsColumn && !rightIsColumn || leftIsUnresolved && !rightIsUnresolved {
 					n.Right = tree.NewStrVal("?")
 				}
@@ -44,26 +82,51 @@ func PostgresqlParse(dbStatementStr *string) (string, error) {
 		},
 	}
 	_, _ = w.Walk(stmts, nil)
-	blueprintStr := stmts.String()
-	return blueprintStr, nil
+	return stmts, nil
 }
-func mysqlReplaceValuesWithPlaceholder(stmt mysqlparser.Statement) mysqlparser.Statement {
+
+// mysqlReplaceValuesWithPlaceholder replaces literals with placeholders, except
+// the ones bound to columns the policy keeps, and restores the original
+// placeholders of the binds renamed by mysqlBinds.
+func mysqlReplaceValuesWithPlaceholder(stmt mysqlparser.Statement, binds map[string]string, policy RedactionPolicy) mysqlparser.Statement {
+	mysqlConstantValues(stmt)
+	kept := mysqlKeptValues(stmt, policy)
+	originals := make([]string, 0, len(binds))
+	for _, placeholder := range binds {
+		originals = append(originals, placeholder)
+	}
+	placeholders := policy.placeholdersAfter(originals)
 	err := mysqlparser.Walk(func(node mysqlparser.SQLNode) (kontinue bool, err error) {
 		switch n := node.(type) {
 
 		case *mysqlparser.Insert:
-			for i, expr := range n.Rows.(mysqlparser.Values) {
+			rows, ok := n.Rows.(mysqlparser.Values)
+			if !ok {
+				break
+			}
+			for i, expr := range rows {
 				for j, val := range expr {
 					if v, ok := val.(*mysqlparser.ColName); ok {
 						v.Name = mysqlparser.NewColIdent("?")
 					}
 					expr[j] = val
 				}
-				n.Rows.(mysqlparser.Values)[i] = expr
+				rows[i] = expr
+			}
+			// multi-row inserts are collapsed to a single row, like IN lists below
+			if len(rows) > 1 {
+				n.Rows = rows[0:1]
 			}
 		case *mysqlparser.SQLVal:
+			if placeholder, ok := binds[string(n.Val)]; ok && n.Type == mysqlparser.ValArg {
+				n.Val = []byte(placeholder)
+				break
+			}
+			if kept[n] {
+				break
+			}
 			n.Type = mysqlparser.ValArg
-			n.Val = []byte("?")
+			n.Val = []byte(placeholders.next())
 
 		case *mysqlparser.ComparisonExpr:
 			if n.Operator == mysqlparser.InStr || n.Operator == mysqlparser.NotInStr {
@@ -81,13 +144,116 @@ func mysqlReplaceValuesWithPlaceholder(stmt mysqlparser.Statement) mysqlparser.S
 	return stmt
 }
 
-func replaceDollarInsideValues(input *string) {
-	if strings.Contains(*input, "$") {
-		re := regexp.MustCompile(`(?i)VALUES\s*\(\s*([^)]+)\)`)
-		// some libraries implement $1 as a placeholder, for example
-		*input = re.ReplaceAllStringFunc(*input, func(match string) string {
-			// Replace '$' only inside the matched "VALUES" expression
-			return strings.ReplaceAll(match, "$", "")
-		})
+// mysqlConstantValues turns the TRUE and FALSE operands and the signed numbers
+// of stmt into values, so that they are redacted like other literals, as
+// PostgreSQL does.
+func mysqlConstantValues(stmt mysqlparser.Statement) {
+	toValue := func(exprs ...*mysqlparser.Expr) {
+		for _, expr := range exprs {
+			switch e := (*expr).(type) {
+			case mysqlparser.BoolVal:
+				*expr = mysqlparser.NewIntVal([]byte(mysqlparser.String(e)))
+			case *mysqlparser.UnaryExpr:
+				if v, ok := e.Expr.(*mysqlparser.SQLVal); ok && (v.Type == mysqlparser.IntVal || v.Type == mysqlparser.FloatVal) &&
+					(e.Operator == mysqlparser.UMinusStr || e.Operator == mysqlparser.UPlusStr) {
+					*expr = mysqlparser.NewFloatVal(append([]byte(e.Operator), v.Val...))
+				}
+			}
+		}
+	}
+	_ = mysqlparser.Walk(func(node mysqlparser.SQLNode) (bool, error) {
+		switch n := node.(type) {
+		case *mysqlparser.ComparisonExpr:
+			toValue(&n.Left, &n.Right)
+		case *mysqlparser.AndExpr:
+			toValue(&n.Left, &n.Right)
+		case *mysqlparser.OrExpr:
+			toValue(&n.Left, &n.Right)
+		case *mysqlparser.NotExpr:
+			toValue(&n.Expr)
+		case *mysqlparser.ParenExpr:
+			toValue(&n.Expr)
+		case *mysqlparser.RangeCond:
+			toValue(&n.From, &n.To)
+		case *mysqlparser.CaseExpr:
+			toValue(&n.Expr, &n.Else)
+		case *mysqlparser.When:
+			toValue(&n.Cond, &n.Val)
+		case *mysqlparser.UpdateExpr:
+			toValue(&n.Expr)
+		case *mysqlparser.AliasedExpr:
+			toValue(&n.Expr)
+		case mysqlparser.ValTuple:
+			for i := range n {
+				toValue(&n[i])
+			}
+		}
+		return true, nil
+	}, stmt)
+}
+
+// mysqlKeptValues returns the values compared to, assigned to or inserted into
+// the columns the policy keeps, see mysqlMarkValues.
+func mysqlKeptValues(stmt mysqlparser.Statement, policy RedactionPolicy) map[*mysqlparser.SQLVal]bool {
+	kept := map[*mysqlparser.SQLVal]bool{}
+	if len(policy.AllowedColumns) == 0 {
+		return kept
+	}
+	keep := func(column string, exprs ...mysqlparser.Expr) {
+		if !policy.keepsColumn(column) {
+			return
+		}
+		for _, expr := range exprs {
+			mysqlMarkValues(kept, expr)
+		}
+	}
+	_ = mysqlparser.Walk(func(node mysqlparser.SQLNode) (bool, error) {
+		switch n := node.(type) {
+		case *mysqlparser.ComparisonExpr:
+			if column, ok := n.Left.(*mysqlparser.ColName); ok {
+				keep(mysqlparser.String(column), n.Right)
+			} else if column, ok := n.Right.(*mysqlparser.ColName); ok {
+				keep(mysqlparser.String(column), n.Left)
+			}
+		case *mysqlparser.RangeCond:
+			if column, ok := n.Left.(*mysqlparser.ColName); ok {
+				keep(mysqlparser.String(column), n.From, n.To)
+			}
+		case *mysqlparser.UpdateExpr:
+			keep(mysqlparser.String(n.Name), n.Expr)
+		case *mysqlparser.Insert:
+			rows, ok := n.Rows.(mysqlparser.Values)
+			if !ok {
+				break
+			}
+			table := mysqlparser.String(n.Table)
+			for _, row := range rows {
+				for i, expr := range row {
+					if i < len(n.Columns) {
+						keep(table+"."+n.Columns[i].String(), expr)
+					}
+				}
+			}
+		}
+		return true, nil
+	}, stmt)
+	return kept
+}
+
+// mysqlMarkValues marks the values bound directly to a column: expr itself, or
+// the elements of a list of values. Values nested in function calls, operators
+// or subqueries are not bound to the column, and are redacted.
+func mysqlMarkValues(kept map[*mysqlparser.SQLVal]bool, expr mysqlparser.Expr) {
+	switch e := expr.(type) {
+	case *mysqlparser.SQLVal:
+		kept[e] = true
+	case *mysqlparser.ParenExpr:
+		mysqlMarkValues(kept, e.Expr)
+	case mysqlparser.ValTuple:
+		for _, element := range e {
+			if v, ok := element.(*mysqlparser.SQLVal); ok {
+				kept[v] = true
+			}
+		}
 	}
 }
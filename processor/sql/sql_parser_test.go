@@ -1,6 +1,8 @@
 package sqlparser
 
 import (
+	"fmt"
+	"strings"
 	"testing"
 )
 
@@ -29,6 +31,12 @@ func TestMysqlParse(t *testing.T) {
 			expected: "select * from users where age > ? and name = ?",
 			wantErr:  false,
 		},
+		{
+			name:     "booleans and signed numbers",
+			input:    "UPDATE users SET active = true, score = -2.5 WHERE banned = FALSE AND verified IS TRUE",
+			expected: "update users set active = ?, score = ? where banned = ? and verified is true",
+			wantErr:  false,
+		},
 		{
 			name:     "select with IN clause",
 			input:    "SELECT * FROM users WHERE id IN (1, 2, 3, 4)",
@@ -95,9 +103,9 @@ func TestPostgresqlParse(t *testing.T) {
 			wantErr:  false,
 		},
 		{
-			name:     "statement with dollar sign in values don't work",
+			name:     "statement with dollar sign in values",
 			input:    "INSERT INTO prices (amount) VALUES ($100.50)",
-			expected: "INSERT INTO prices(amount) VALUES (100.50)",
+			expected: "INSERT INTO prices(amount) VALUES ('?')",
 			wantErr:  false,
 		},
 		{
@@ -144,3 +152,275 @@ func TestPostgresqlParse(t *testing.T) {
 		})
 	}
 }
+
+func TestPostgresqlParseRedactsAllLiterals(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		literals []string
+	}{
+		{
+			name:     "multi row insert",
+			input:    "INSERT INTO users (name, email) VALUES ('Alice', 'alice@example.com'), ('Bob', 'bob@example.com')",
+			literals: []string{"Alice", "alice@example.com", "Bob", "bob@example.com"},
+		},
+		{
+			name:     "insert on conflict",
+			input:    "INSERT INTO users (id, name) VALUES (4711, 'Carol') ON CONFLICT (id) DO UPDATE SET name = 'Dave' RETURNING id",
+			literals: []string{"4711", "Carol", "Dave"},
+		},
+		{
+			name:     "update set",
+			input:    "UPDATE accounts SET balance = 1234.56, owner = 'Eve' WHERE id = 98765",
+			literals: []string{"1234.56", "Eve", "98765"},
+		},
+		{
+			name:     "limit and offset",
+			input:    "SELECT * FROM orders ORDER BY created_at LIMIT 25 OFFSET 750",
+			literals: []string{"25", "750"},
+		},
+		{
+			name:     "function arguments",
+			input:    "SELECT * FROM users WHERE lower(email) = lower('Frank@Example.com') AND substr(phone, 1, 3) = '555'",
+			literals: []string{"Frank@Example.com", "555"},
+		},
+		{
+			name:     "case expression",
+			input:    "SELECT CASE WHEN salary > 90000 THEN 'executive' ELSE 'staff' END FROM employees",
+			literals: []string{"90000", "executive", "staff"},
+		},
+		{
+			name:     "array literal",
+			input:    "SELECT * FROM users WHERE id = ANY(ARRAY[31337, 42424])",
+			literals: []string{"31337", "42424"},
+		},
+		{
+			name:     "typed literals",
+			input:    "SELECT * FROM events WHERE created_at > '2023-07-14'::DATE AND duration < INTERVAL '3 days'",
+			literals: []string{"2023-07-14", "3 days"},
+		},
+		{
+			name:     "subquery",
+			input:    "SELECT * FROM users WHERE id IN (SELECT user_id FROM orders WHERE total > 5150)",
+			literals: []string{"5150"},
+		},
+		{
+			name:     "delete",
+			input:    "DELETE FROM sessions WHERE token = 'f3a9c2e1' OR expires_at < 1700000000",
+			literals: []string{"f3a9c2e1", "1700000000"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := PostgresqlParse(&tt.input)
+			if err != nil {
+				t.Fatalf("postgresqlParse() error = %v", err)
+			}
+			for _, literal := range tt.literals {
+				if strings.Contains(got, literal) {
+					t.Errorf("postgresqlParse() = %v, leaks literal %v", got, literal)
+				}
+			}
+		})
+	}
+}
+
+func TestPostgresqlParseWithMode(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		mode     RedactionMode
+		expected string
+	}{
+		{
+			name:     "redact all",
+			input:    "UPDATE users SET name = 'Alice' WHERE id = 123 AND deleted_at IS NULL",
+			mode:     RedactAll,
+			expected: "UPDATE users SET name = '?' WHERE (id = '?') AND (deleted_at IS NULL)",
+		},
+		{
+			name:     "redact comparisons",
+			input:    "SELECT name FROM users WHERE id = 123 LIMIT 10",
+			mode:     RedactComparisons,
+			expected: "SELECT name FROM users WHERE id = '?' LIMIT 10",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := PostgresqlParseWithMode(&tt.input, tt.mode)
+			if err != nil {
+				t.Fatalf("postgresqlParseWithMode() error = %v", err)
+			}
+			if got != tt.expected {
+				t.Errorf("postgresqlParseWithMode() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func batchQuery(prefix string, format string, separator string, size int) string {
+	items := make([]string, size)
+	for i := range items {
+		items[i] = strings.ReplaceAll(format, "%d", fmt.Sprint(i+1))
+	}
+	return prefix + strings.Join(items, separator)
+}
+
+func TestParseCollapsesBatches(t *testing.T) {
+	tests := []struct {
+		name     string
+		parse    func(*string) (string, error)
+		query    func(size int) string
+		expected string
+	}{
+		{
+			name:  "mysql multi row insert",
+			parse: MysqlParse,
+			query: func(size int) string {
+				return batchQuery("INSERT INTO users (id, name) VALUES ", "(%d, 'user%d')", ", ", size)
+			},
+			expected: "insert into users(id, name) values (?, ?)",
+		},
+		{
+			name:     "mysql in list",
+			parse:    MysqlParse,
+			query:    func(size int) string { return batchQuery("SELECT * FROM users WHERE id IN (", "%d", ", ", size) + ")" },
+			expected: "select * from users where id in (?)",
+		},
+		{
+			name:  "postgresql multi row insert",
+			parse: PostgresqlParse,
+			query: func(size int) string {
+				return batchQuery("INSERT INTO users (id, name) VALUES ", "(%d, 'user%d')", ", ", size)
+			},
+			expected: "INSERT INTO users(id, name) VALUES ('?', '?')",
+		},
+		{
+			name:     "postgresql in list",
+			parse:    PostgresqlParse,
+			query:    func(size int) string { return batchQuery("SELECT * FROM users WHERE id IN (", "%d", ", ", size) + ")" },
+			expected: "SELECT * FROM users WHERE id IN ('?')",
+		},
+		{
+			name:  "postgresql not in list",
+			parse: PostgresqlParse,
+			query: func(size int) string {
+				return batchQuery("SELECT * FROM users WHERE id NOT IN (", "%d", ", ", size) + ")"
+			},
+			expected: "SELECT * FROM users WHERE id NOT IN ('?')",
+		},
+		{
+			name:  "postgresql any array",
+			parse: PostgresqlParse,
+			query: func(size int) string {
+				return batchQuery("SELECT * FROM users WHERE id = ANY(ARRAY[", "%d", ", ", size) + "])"
+			},
+			expected: "SELECT * FROM users WHERE id = ANY (ARRAY['?'])",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, size := range []int{1, 2, 10, 1000} {
+				query := tt.query(size)
+				got, err := tt.parse(&query)
+				if err != nil {
+					t.Fatalf("parse() size %d error = %v", size, err)
+				}
+				if got != tt.expected {
+					t.Errorf("parse() size %d = %v, want %v", size, got, tt.expected)
+				}
+			}
+		})
+	}
+}
+
+func TestParsePreservesBindPlaceholders(t *testing.T) {
+	tests := []struct {
+		name     string
+		parse    func(*string) (string, error)
+		input    string
+		expected string
+	}{
+		{
+			name:     "postgresql numbered binds in values",
+			parse:    PostgresqlParse,
+			input:    "INSERT INTO users (id, name) VALUES ($1, $2)",
+			expected: "INSERT INTO users(id, name) VALUES ($1, $2)",
+		},
+		{
+			name:     "postgresql binds next to literals",
+			parse:    PostgresqlParse,
+			input:    "SELECT * FROM orders WHERE customer_id = $1 AND status = 'open' AND total > 10",
+			expected: "SELECT * FROM orders WHERE ((customer_id = $1) AND (status = '?')) AND (total > '?')",
+		},
+		{
+			name:     "postgresql money string",
+			parse:    PostgresqlParse,
+			input:    "UPDATE prices SET label = '$100', amount = $1 WHERE id = $2",
+			expected: "UPDATE prices SET label = '?', amount = $1 WHERE id = $2",
+		},
+		{
+			name:     "postgresql money literal",
+			parse:    PostgresqlParse,
+			input:    "INSERT INTO prices (amount, currency) VALUES ($100.50, $1)",
+			expected: "INSERT INTO prices(amount, currency) VALUES ('?', $1)",
+		},
+		{
+			name:     "postgresql dollar quoted strings",
+			parse:    PostgresqlParse,
+			input:    "SELECT $$it's $1.50; really$$, $tag$ $2 $tag$ FROM notes WHERE id = $1",
+			expected: "SELECT '?', '?' FROM notes WHERE id = $1",
+		},
+		{
+			name:     "mysql positional binds",
+			parse:    MysqlParse,
+			input:    "SELECT * FROM users WHERE id = ? AND name = 'bob' AND age IN (?, ?, ?)",
+			expected: "select * from users where id = ? and name = ? and age in (?)",
+		},
+		{
+			name:     "mysql named binds",
+			parse:    MysqlParse,
+			input:    "UPDATE users SET name = :name WHERE id = :id AND note = ':not_a_bind'",
+			expected: "update users set name = :name where id = :id and note = ?",
+		},
+		{
+			name:     "mysql numbered binds",
+			parse:    MysqlParse,
+			input:    "INSERT INTO users (id, name) VALUES ($1, $2)",
+			expected: "insert into users(id, name) values ($1, $2)",
+		},
+		{
+			name:     "mysql money string",
+			parse:    MysqlParse,
+			input:    "INSERT INTO prices (label, amount) VALUES ('$100', $1)",
+			expected: "insert into prices(label, amount) values (?, $1)",
+		},
+		{
+			name:     "mssql binds",
+			parse:    MssqlParse,
+			input:    "SELECT * FROM users WHERE id = @p1 AND balance > $10.50",
+			expected: "SELECT * FROM users WHERE id = @p1 AND balance > ?",
+		},
+		{
+			name:     "oracle binds",
+			parse:    OracleParse,
+			input:    "SELECT * FROM users WHERE id = :id AND name = ':id'",
+			expected: "SELECT * FROM users WHERE id = :id AND name = ?",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.parse(&tt.input)
+			if err != nil {
+				t.Fatalf("parse() error = %v", err)
+			}
+			if got != tt.expected {
+				t.Errorf("parse() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
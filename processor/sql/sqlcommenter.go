@@ -0,0 +1,147 @@
+package sqlparser
+
+import (
+	"context"
+	"net/url"
+	"sort"
+	"strings"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
+)
+
+// SqlCommenterAttributePrefix prefixes the attributes holding the sqlcommenter
+// tags of a query, e.g. db.sqlcommenter.controller.
+const SqlCommenterAttributePrefix = "db.sqlcommenter."
+
+// ExtractSqlCommenter returns the tags of the sqlcommenter comment ending query,
+// such as /*controller='users',route='%2Fusers%2F%3Aid'*/, decoded. It returns
+// nil when the query does not end with such a comment.
+func ExtractSqlCommenter(query string) map[string]string {
+	comment, ok := trailingComment(query)
+	if !ok {
+		return nil
+	}
+	tags := map[string]string{}
+	for _, pair := range splitSqlCommenterPairs(comment) {
+		key, value, found := strings.Cut(pair, "=")
+		if !found || len(value) < 2 || value[0] != '\'' || value[len(value)-1] != '\'' {
+			return nil
+		}
+		key, err := url.PathUnescape(strings.TrimSpace(key))
+		if err != nil {
+			return nil
+		}
+		value, err = url.PathUnescape(strings.ReplaceAll(value[1:len(value)-1], `\'`, `'`))
+		if err != nil {
+			return nil
+		}
+		tags[key] = value
+	}
+	if len(tags) == 0 {
+		return nil
+	}
+	return tags
+}
+
+// SqlCommenterAttributes returns the sqlcommenter tags of query as attributes,
+// with the same signature as the span formatters so it can be used as an
+// attributes getter. The traceparent and tracestate tags are left out, as they
+// only repeat the trace context of the span.
+func SqlCommenterAttributes(ctx context.Context, method string, query string) []attribute.KeyValue {
+	tags := ExtractSqlCommenter(query)
+	keys := make([]string, 0, len(tags))
+	for key := range tags {
+		if key != "traceparent" && key != "tracestate" {
+			keys = append(keys, key)
+		}
+	}
+	if len(keys) == 0 {
+		return nil
+	}
+	sort.Strings(keys)
+	attributes := make([]attribute.KeyValue, 0, len(keys))
+	for _, key := range keys {
+		attributes = append(attributes, attribute.String(SqlCommenterAttributePrefix+key, tags[key]))
+	}
+	return attributes
+}
+
+// InjectSqlCommenter appends a sqlcommenter comment to query holding tags and
+// the traceparent and tracestate of the span in ctx, so that database side logs
+// and query insights can be correlated with the trace, cgx_transaction included.
+// Queries already ending with a comment are returned unchanged, as are queries
+// for which there is nothing to add.
+func InjectSqlCommenter(ctx context.Context, query string, tags map[string]string) string {
+	if _, ok := trailingComment(query); ok {
+		return query
+	}
+	carrier := propagation.MapCarrier{}
+	for key, value := range tags {
+		carrier[key] = value
+	}
+	propagation.TraceContext{}.Inject(ctx, carrier)
+	if len(carrier) == 0 {
+		return query
+	}
+	keys := carrier.Keys()
+	sort.Strings(keys)
+	pairs := make([]string, 0, len(keys))
+	for _, key := range keys {
+		pairs = append(pairs, sqlCommenterEscape(key)+"='"+sqlCommenterEscape(carrier[key])+"'")
+	}
+	comment := "/*" + strings.Join(pairs, ",") + "*/"
+
+	// the comment goes before the terminating semicolon, if any
+	trimmed := strings.TrimRight(query, " \t\r\n")
+	if strings.HasSuffix(trimmed, ";") {
+		return strings.TrimRight(strings.TrimSuffix(trimmed, ";"), " \t\r\n") + " " + comment + ";"
+	}
+	return trimmed + " " + comment
+}
+
+// sqlCommenterEscape percent-encodes s as the sqlcommenter specification
+// requires, spaces as %20 rather than +, which is kept as is by parsers.
+func sqlCommenterEscape(s string) string {
+	// QueryEscape encodes + as %2B, so any + left stands for a space
+	return strings.ReplaceAll(url.QueryEscape(s), "+", "%20")
+}
+
+// trailingComment returns the content of the /*...*/ comment ending query,
+// ignoring a terminating semicolon.
+func trailingComment(query string) (string, bool) {
+	query = strings.TrimRight(query, " \t\r\n")
+	query = strings.TrimRight(strings.TrimSuffix(query, ";"), " \t\r\n")
+	if !strings.HasSuffix(query, "*/") {
+		return "", false
+	}
+	start := strings.LastIndex(query, "/*")
+	if start < 0 || start+2 > len(query)-2 {
+		return "", false
+	}
+	return query[start+2 : len(query)-2], true
+}
+
+// splitSqlCommenterPairs splits a comment on the commas outside quoted values.
+func splitSqlCommenterPairs(comment string) []string {
+	var pairs []string
+	quoted := false
+	start := 0
+	for i := 0; i < len(comment); i++ {
+		switch comment[i] {
+		case '\\':
+			i++
+		case '\'':
+			quoted = !quoted
+		case ',':
+			if !quoted {
+				pairs = append(pairs, strings.TrimSpace(comment[start:i]))
+				start = i + 1
+			}
+		}
+	}
+	if last := strings.TrimSpace(comment[start:]); last != "" {
+		pairs = append(pairs, last)
+	}
+	return pairs
+}
@@ -0,0 +1,214 @@
+package sqlparser
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"go.opentelemetry.io/otel/attribute"
+	traceSdk "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	traceCore "go.opentelemetry.io/otel/trace"
+)
+
+func TestParseStripsComments(t *testing.T) {
+	tests := []struct {
+		name     string
+		parse    func(*string) (string, error)
+		input    string
+		expected string
+	}{
+		{
+			name:     "mysql sqlcommenter",
+			parse:    MysqlParse,
+			input:    "SELECT * FROM users WHERE id = 1 /*controller='users',traceparent='00-5bd66ef5095369c7b0d1f8f4bd33716a-c532cb4098ac3dd2-01'*/",
+			expected: "select * from users where id = ?",
+		},
+		{
+			name:     "mysql optimizer hint",
+			parse:    MysqlParse,
+			input:    "SELECT /*+ MAX_EXECUTION_TIME(1000) */ name FROM users WHERE id = 1",
+			expected: "select name from users where id = ?",
+		},
+		{
+			name:     "mysql line comments",
+			parse:    MysqlParse,
+			input:    "SELECT name -- user 'alice'\nFROM users # where id = 'x\nWHERE id = 1",
+			expected: "select name from users where id = ?",
+		},
+		{
+			name:     "postgresql comments with literals",
+			parse:    PostgresqlParse,
+			input:    "/* app='billing' */ UPDATE invoices SET paid = true WHERE id = $1 -- customer 'acme'",
+			expected: "UPDATE invoices SET paid = '?' WHERE id = $1",
+		},
+		{
+			name:     "postgresql comment inside a string is kept",
+			parse:    PostgresqlParse,
+			input:    "SELECT '/* not a comment */' AS note",
+			expected: "SELECT '?' AS note",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.parse(&tt.input)
+			if err != nil {
+				t.Fatalf("parse() error = %v", err)
+			}
+			if got != tt.expected {
+				t.Errorf("parse() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestExtractSqlCommenter(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected map[string]string
+	}{
+		{
+			name:  "tags",
+			input: "SELECT * FROM users /*action='list',controller='users',route='%2Fusers%2F%3Aid'*/",
+			expected: map[string]string{
+				"action":     "list",
+				"controller": "users",
+				"route":      "/users/:id",
+			},
+		},
+		{
+			name:     "terminating semicolon",
+			input:    "SELECT 1 /*framework='gorm%3Av2'*/;",
+			expected: map[string]string{"framework": "gorm:v2"},
+		},
+		{
+			name:     "escaped quote and comma",
+			input:    `SELECT 1 /*note='it\'s,fine'*/`,
+			expected: map[string]string{"note": "it's,fine"},
+		},
+		{
+			name:     "percent-encoded space and plus sign",
+			input:    "SELECT 1 /*action='list%20all',framework='c+%2B'*/",
+			expected: map[string]string{"action": "list all", "framework": "c++"},
+		},
+		{
+			name:  "no comment",
+			input: "SELECT 1",
+		},
+		{
+			name:  "plain comment",
+			input: "SELECT 1 /* cached */",
+		},
+		{
+			name:  "comment not at the end",
+			input: "SELECT /*controller='users'*/ 1",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ExtractSqlCommenter(tt.input); !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("ExtractSqlCommenter() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestSqlCommenterAttributes(t *testing.T) {
+	query := "SELECT 1 /*controller='users',traceparent='00-5bd66ef5095369c7b0d1f8f4bd33716a-c532cb4098ac3dd2-01'*/"
+	expected := []attribute.KeyValue{attribute.String("db.sqlcommenter.controller", "users")}
+	if got := SqlCommenterAttributes(context.Background(), "query", query); !reflect.DeepEqual(got, expected) {
+		t.Errorf("SqlCommenterAttributes() = %v, want %v", got, expected)
+	}
+	if got := SqlCommenterAttributes(context.Background(), "query", "SELECT 1"); got != nil {
+		t.Errorf("SqlCommenterAttributes() = %v, want nil", got)
+	}
+}
+
+func TestInjectSqlCommenter(t *testing.T) {
+	traceID, _ := traceCore.TraceIDFromHex("5bd66ef5095369c7b0d1f8f4bd33716a")
+	spanID, _ := traceCore.SpanIDFromHex("c532cb4098ac3dd2")
+	state, _ := traceCore.ParseTraceState("cgx_transaction=flow1,cgx_transaction_distributed=flow1")
+	ctx := traceCore.ContextWithSpanContext(context.Background(), traceCore.NewSpanContext(traceCore.SpanContextConfig{
+		TraceID:    traceID,
+		SpanID:     spanID,
+		TraceFlags: traceCore.FlagsSampled,
+		TraceState: state,
+	}))
+
+	tests := []struct {
+		name     string
+		ctx      context.Context
+		query    string
+		tags     map[string]string
+		expected string
+	}{
+		{
+			name:     "trace context",
+			ctx:      ctx,
+			query:    "SELECT * FROM users",
+			expected: "SELECT * FROM users /*traceparent='00-5bd66ef5095369c7b0d1f8f4bd33716a-c532cb4098ac3dd2-01',tracestate='cgx_transaction%3Dflow1%2Ccgx_transaction_distributed%3Dflow1'*/",
+		},
+		{
+			name:     "tags before semicolon",
+			ctx:      context.Background(),
+			query:    "SELECT 1;",
+			tags:     map[string]string{"route": "/users/:id", "controller": "users"},
+			expected: "SELECT 1 /*controller='users',route='%2Fusers%2F%3Aid'*/;",
+		},
+		{
+			name:     "spaces and plus signs",
+			ctx:      context.Background(),
+			query:    "SELECT 1",
+			tags:     map[string]string{"action": "list all", "framework": "c++"},
+			expected: "SELECT 1 /*action='list%20all',framework='c%2B%2B'*/",
+		},
+		{
+			name:     "nothing to inject",
+			ctx:      context.Background(),
+			query:    "SELECT 1",
+			expected: "SELECT 1",
+		},
+		{
+			name:     "existing comment",
+			ctx:      ctx,
+			query:    "SELECT 1 /*controller='users'*/",
+			expected: "SELECT 1 /*controller='users'*/",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := InjectSqlCommenter(tt.ctx, tt.query, tt.tags); got != tt.expected {
+				t.Errorf("InjectSqlCommenter() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+
+	injected := InjectSqlCommenter(ctx, "SELECT 1", map[string]string{"controller": "users", "action": "list all+"})
+	extracted := ExtractSqlCommenter(injected)
+	if extracted["tracestate"] != state.String() || extracted["controller"] != "users" || extracted["action"] != "list all+" {
+		t.Errorf("ExtractSqlCommenter(InjectSqlCommenter()) = %v", extracted)
+	}
+}
+
+func TestSpanProcessorSqlCommenterAttributes(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := traceSdk.NewTracerProvider(traceSdk.WithSpanProcessor(NewSpanProcessor(recorder, WithSqlCommenterAttributes())))
+	_, span := provider.Tracer("test").Start(context.Background(), "query")
+	span.SetAttributes(
+		attribute.String(DBSystemAttribute, "postgresql"),
+		attribute.String(DBStatementAttribute, "SELECT * FROM users WHERE id = 1 /*controller='users'*/"),
+	)
+	span.End()
+
+	ended := recorder.Ended()[0]
+	if statement, _ := attributeValue(ended, DBStatementAttribute); statement != "SELECT * FROM users WHERE id = '?'" {
+		t.Errorf("%v = %v", DBStatementAttribute, statement)
+	}
+	if controller, _ := attributeValue(ended, "db.sqlcommenter.controller"); controller != "users" {
+		t.Errorf("db.sqlcommenter.controller = %v, want users", controller)
+	}
+}
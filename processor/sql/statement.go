@@ -0,0 +1,192 @@
+package sqlparser
+
+import (
+	"strings"
+
+	"github.com/auxten/postgresql-parser/pkg/sql/sem/tree"
+	mysqlparser "github.com/xwb1989/sqlparser"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// StatementInfo describes a normalized statement for span attributes.
+type StatementInfo struct {
+	// Normalized is the statement with its literals redacted, as returned by the Parse functions.
+	Normalized string
+	// Operation is the statement verb, e.g. SELECT or INSERT.
+	Operation string
+	// Table is the first table the statement reads from or writes to, if any.
+	Table string
+	// Procedure is the stored procedure called by CALL, EXEC or EXECUTE statements.
+	Procedure string
+}
+
+// Attributes returns the db.statement, db.operation, db.sql.table and
+// db.stored_procedure.name attributes of the statement, omitting the ones that
+// are unknown.
+func (i StatementInfo) Attributes() []attribute.KeyValue {
+	attributes := []attribute.KeyValue{attribute.String(DBStatementAttribute, i.Normalized)}
+	if i.Operation != "" {
+		attributes = append(attributes, attribute.String(DBOperationAttribute, i.Operation))
+	}
+	if i.Table != "" {
+		attributes = append(attributes, attribute.String(DBTableAttribute, i.Table))
+	}
+	if i.Procedure != "" {
+		attributes = append(attributes, attribute.String(DBStoredProcedureAttribute, i.Procedure))
+	}
+	return attributes
+}
+
+// MysqlStatementInfo normalizes a MySQL statement and reports its operation and main table.
+// Scripts of several statements are normalized statement by statement and
+// reported by their first statement.
+func MysqlStatementInfo(query string) (StatementInfo, error) {
+	return mysqlScriptInfo(query, RedactionPolicy{})
+}
+
+func mysqlScriptInfo(query string, policy RedactionPolicy) (StatementInfo, error) {
+	return guard(query, func() (StatementInfo, error) {
+		return mysqlUnguardedScriptInfo(query, policy)
+	}, func() (StatementInfo, error) {
+		return lexicalStatementInfo(mysqlLexicalDialect, query, policy)
+	})
+}
+
+func mysqlUnguardedScriptInfo(query string, policy RedactionPolicy) (StatementInfo, error) {
+	statements, err := mysqlparser.SplitStatementToPieces(query)
+	if err != nil {
+		return StatementInfo{}, err
+	}
+	return scriptInfo(mysqlLexicalDialect, statements, policy, func(statement string) (StatementInfo, error) {
+		return mysqlStatementInfo(statement, policy)
+	})
+}
+
+func mysqlStatementInfo(query string, policy RedactionPolicy) (StatementInfo, error) {
+	operation := strings.ToUpper(mysqlparser.StmtType(mysqlparser.Preview(query)))
+	stmt, err := mysqlNormalize(query, policy)
+	if err != nil {
+		return StatementInfo{}, err
+	}
+	return StatementInfo{
+		Normalized: mysqlparser.String(stmt),
+		Operation:  operation,
+		Table:      mysqlStatementTable(stmt),
+	}, nil
+}
+
+// PostgresqlStatementInfo normalizes a PostgreSQL statement and reports its operation and main table.
+// Scripts of several statements are reported by their first statement.
+func PostgresqlStatementInfo(query string) (StatementInfo, error) {
+	return postgresqlScriptInfo(query, RedactAll, RedactionPolicy{})
+}
+
+func postgresqlScriptInfo(query string, mode RedactionMode, policy RedactionPolicy) (StatementInfo, error) {
+	return guard(query, func() (StatementInfo, error) {
+		return postgresqlUnguardedScriptInfo(query, mode, policy)
+	}, func() (StatementInfo, error) {
+		return lexicalStatementInfo(postgresqlLexicalDialect, query, policy)
+	})
+}
+
+// postgresqlUnguardedScriptInfo falls back to normalizing statements one by one
+// when the script contains CALL statements, which the parser does not support.
+func postgresqlUnguardedScriptInfo(query string, mode RedactionMode, policy RedactionPolicy) (StatementInfo, error) {
+	info, err := postgresqlStatementInfo(query, mode, policy)
+	if err == nil {
+		return info, nil
+	}
+	statements, splitErr := postgresqlLexicalDialect.splitStatements(query)
+	if splitErr != nil || !containsProcedureCall(postgresqlLexicalDialect, statements) {
+		return StatementInfo{}, err
+	}
+	return scriptInfo(postgresqlLexicalDialect, statements, policy, func(statement string) (StatementInfo, error) {
+		return postgresqlStatementInfo(statement, mode, policy)
+	})
+}
+
+func postgresqlStatementInfo(query string, mode RedactionMode, policy RedactionPolicy) (StatementInfo, error) {
+	stmts, err := postgresqlNormalize(query, mode, policy)
+	if err != nil {
+		return StatementInfo{}, err
+	}
+	if len(stmts) == 0 {
+		return StatementInfo{}, errEmptyStatement
+	}
+	return StatementInfo{
+		Normalized: stmts.String(),
+		Operation:  stmts[0].AST.StatementTag(),
+		Table:      postgresqlStatementTable(stmts[0].AST),
+	}, nil
+}
+
+func mysqlStatementTable(stmt mysqlparser.Statement) string {
+	switch n := stmt.(type) {
+	case *mysqlparser.Select:
+		return mysqlTableExprsName(n.From)
+	case *mysqlparser.Insert:
+		return mysqlparser.String(n.Table)
+	case *mysqlparser.Update:
+		return mysqlTableExprsName(n.TableExprs)
+	case *mysqlparser.Delete:
+		return mysqlTableExprsName(n.TableExprs)
+	case *mysqlparser.Union:
+		return mysqlStatementTable(n.Left)
+	case *mysqlparser.ParenSelect:
+		return mysqlStatementTable(n.Select)
+	}
+	return ""
+}
+
+func mysqlTableExprsName(exprs mysqlparser.TableExprs) string {
+	if len(exprs) == 0 {
+		return ""
+	}
+	switch n := exprs[0].(type) {
+	case *mysqlparser.AliasedTableExpr:
+		if name, ok := n.Expr.(mysqlparser.TableName); ok {
+			return mysqlparser.String(name)
+		}
+	case *mysqlparser.JoinTableExpr:
+		return mysqlTableExprsName(mysqlparser.TableExprs{n.LeftExpr})
+	case *mysqlparser.ParenTableExpr:
+		return mysqlTableExprsName(n.Exprs)
+	}
+	return ""
+}
+
+func postgresqlStatementTable(stmt tree.Statement) string {
+	switch n := stmt.(type) {
+	case *tree.Select:
+		return postgresqlStatementTable(n.Select)
+	case *tree.ParenSelect:
+		return postgresqlStatementTable(n.Select)
+	case *tree.SelectClause:
+		if len(n.From.Tables) > 0 {
+			return postgresqlTableExprName(n.From.Tables[0])
+		}
+	case *tree.UnionClause:
+		return postgresqlStatementTable(n.Left)
+	case *tree.Insert:
+		return postgresqlTableExprName(n.Table)
+	case *tree.Update:
+		return postgresqlTableExprName(n.Table)
+	case *tree.Delete:
+		return postgresqlTableExprName(n.Table)
+	}
+	return ""
+}
+
+func postgresqlTableExprName(expr tree.TableExpr) string {
+	switch n := expr.(type) {
+	case *tree.TableName:
+		return n.String()
+	case *tree.AliasedTableExpr:
+		return postgresqlTableExprName(n.Expr)
+	case *tree.JoinTableExpr:
+		return postgresqlTableExprName(n.Left)
+	case *tree.ParenTableExpr:
+		return postgresqlTableExprName(n.Expr)
+	}
+	return ""
+}
@@ -0,0 +1,57 @@
+package sqlparser
+
+import (
+	"testing"
+)
+
+func TestStatementInfo(t *testing.T) {
+	tests := []struct {
+		name     string
+		describe func(string) (StatementInfo, error)
+		input    string
+		expected StatementInfo
+	}{
+		{
+			name:     "mysql select with join",
+			describe: MysqlStatementInfo,
+			input:    "SELECT u.name FROM users AS u JOIN orders AS o ON u.id = o.user_id WHERE o.total > 10",
+			expected: StatementInfo{Normalized: "select u.name from users as u join orders as o on u.id = o.user_id where o.total > ?", Operation: "SELECT", Table: "users"},
+		},
+		{
+			name:     "mysql insert",
+			describe: MysqlStatementInfo,
+			input:    "INSERT INTO shop.orders (id) VALUES (1)",
+			expected: StatementInfo{Normalized: "insert into shop.orders(id) values (?)", Operation: "INSERT", Table: "shop.orders"},
+		},
+		{
+			name:     "postgresql update",
+			describe: PostgresqlStatementInfo,
+			input:    "UPDATE accounts SET balance = 10 WHERE id = 1",
+			expected: StatementInfo{Normalized: "UPDATE accounts SET balance = '?' WHERE id = '?'", Operation: "UPDATE", Table: "accounts"},
+		},
+		{
+			name:     "postgresql delete",
+			describe: PostgresqlStatementInfo,
+			input:    "DELETE FROM public.sessions WHERE token = 'abc'",
+			expected: StatementInfo{Normalized: "DELETE FROM public.sessions WHERE token = '?'", Operation: "DELETE", Table: "public.sessions"},
+		},
+		{
+			name:     "postgresql select without table",
+			describe: PostgresqlStatementInfo,
+			input:    "SELECT 1",
+			expected: StatementInfo{Normalized: "SELECT '?'", Operation: "SELECT"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.describe(tt.input)
+			if err != nil {
+				t.Fatalf("statementInfo() error = %v", err)
+			}
+			if got != tt.expected {
+				t.Errorf("statementInfo() = %+v, want %+v", got, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1 @@
+SELECT * FROM users WHERE id = ANY(ARRAY[1, 2, 3])
@@ -0,0 +1 @@
+SELECT * FROM users WHERE id = $1 AND tenant = $2 AND name = 'x'
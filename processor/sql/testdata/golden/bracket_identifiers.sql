@@ -0,0 +1 @@
+SELECT [name] FROM [dbo].[users] WHERE [id] = @p1 AND [email] = N'x@y.z'
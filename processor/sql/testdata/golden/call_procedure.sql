@@ -0,0 +1 @@
+CALL refresh_totals(2024, 'Q1')
@@ -0,0 +1 @@
+SELECT CASE WHEN score > 90 THEN 'A' WHEN score > 80 THEN 'B' ELSE 'C' END FROM grades WHERE term = 'fall'
@@ -0,0 +1,2 @@
+/* app='billing' */ SELECT total -- the amount
+FROM invoices WHERE id = 5 /*traceparent='00-5bd66ef5095369c7b0d1f8f4bd33716a-c532cb4098ac3dd2-01'*/
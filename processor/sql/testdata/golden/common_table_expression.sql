@@ -0,0 +1 @@
+WITH recent AS (SELECT id FROM orders WHERE created_at > '2024-01-01') SELECT count(*) FROM recent WHERE id > 10
@@ -0,0 +1 @@
+DELETE FROM sessions WHERE expires_at < '2024-06-01' OR user_id = 7
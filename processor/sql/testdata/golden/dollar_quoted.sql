@@ -0,0 +1 @@
+SELECT $$it's $1.50; really$$ AS note FROM notes WHERE id = $1
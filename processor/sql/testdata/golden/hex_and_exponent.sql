@@ -0,0 +1 @@
+SELECT * FROM metrics WHERE value > 1.5e10 AND flags = 0xFF
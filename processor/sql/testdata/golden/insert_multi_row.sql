@@ -0,0 +1 @@
+INSERT INTO events (kind, payload, created_at) VALUES ('login', '{"ip": "10.0.0.1"}', '2024-01-01'), ('logout', '{}', '2024-01-02')
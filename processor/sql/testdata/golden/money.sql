@@ -0,0 +1 @@
+INSERT INTO prices (label, amount) VALUES ('$100', $100.50)
@@ -0,0 +1 @@
+EXEC dbo.usp_add_user @name = N'bob', @age = 42
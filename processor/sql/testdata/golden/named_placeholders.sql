@@ -0,0 +1 @@
+UPDATE users SET name = :name WHERE id = :id AND note = ':literal'
@@ -0,0 +1 @@
+SELECT * FROM users WHERE deleted_at IS NULL AND active = true AND score = 0
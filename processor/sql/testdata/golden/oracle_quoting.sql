@@ -0,0 +1 @@
+SELECT q'[it's]' AS quote FROM dual WHERE id = :1
@@ -0,0 +1 @@
+UPDATE orders SET status = lower('SECRET') WHERE status = 'new' AND status <> upper('hidden')
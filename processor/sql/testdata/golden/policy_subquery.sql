@@ -0,0 +1 @@
+SELECT * FROM orders WHERE status = (SELECT s FROM users WHERE email = 'alice@example.com') AND status IN ('new', 'paid')
@@ -0,0 +1 @@
+SELECT * FROM users WHERE id = ? AND name = ? AND active = 1
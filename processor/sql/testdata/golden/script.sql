@@ -0,0 +1 @@
+BEGIN; UPDATE stock SET qty = qty - 1 WHERE sku = 'A-1'; INSERT INTO moves (sku, qty) VALUES ('A-1', -1); COMMIT
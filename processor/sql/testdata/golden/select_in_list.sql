@@ -0,0 +1 @@
+SELECT * FROM orders WHERE status IN ('new', 'paid', 'shipped') AND id NOT IN (1, 2, 3)
@@ -0,0 +1,6 @@
+SELECT u.name, o.total
+FROM users AS u
+JOIN orders AS o ON o.user_id = u.id
+WHERE o.total > 99.95
+ORDER BY o.total DESC
+LIMIT 10
@@ -0,0 +1 @@
+SELECT id, name FROM users WHERE email = 'alice@example.com' AND age >= 21
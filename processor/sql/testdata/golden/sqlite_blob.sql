@@ -0,0 +1 @@
+INSERT INTO files (name, data) VALUES ('a.bin', X'DEADBEEF')
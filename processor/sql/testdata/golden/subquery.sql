@@ -0,0 +1 @@
+SELECT name FROM users WHERE id IN (SELECT user_id FROM orders WHERE total > 100) AND created_at > '2024-01-01'
@@ -0,0 +1 @@
+UPDATE accounts SET balance = balance - 25.50, note = 'it''s done' WHERE id = 42